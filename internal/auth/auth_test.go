@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/riyanathariq/dana-enterprise/internal/config"
+)
+
+// keyHash returns the api_key_sha256 of an API key
+func keyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// sign returns the signature a client holding secret sends for a request
+func sign(secret, method, pathWithQuery string, body []byte, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(StringToSign(method, pathWithQuery, body, timestamp)))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// testAuthenticator has a signing client "shop" (key-shop) and a key-only client "ops" (key-ops)
+func testAuthenticator(t *testing.T) *Authenticator {
+	t.Helper()
+	a, err := NewAuthenticator(config.AuthConfig{
+		SignatureMaxSkew: 5 * time.Minute,
+		Clients: []config.APIClient{
+			{ID: "shop", APIKeySHA256: keyHash("key-shop"), HMACSecret: "secret-shop", Scopes: []string{ScopeOrdersWrite}},
+			{ID: "ops", APIKeySHA256: keyHash("key-ops"), Scopes: []string{ScopeOrdersRead, ScopeRefundsRead}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewAuthenticator: %v", err)
+	}
+	return a
+}
+
+func TestNewAuthenticatorRejectsInvalidClients(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  config.APIClient
+		wantErr string
+	}{
+		{
+			name:    "unknown scope",
+			client:  config.APIClient{ID: "shop", APIKeySHA256: keyHash("key-shop"), Scopes: []string{"orders:delete"}},
+			wantErr: `API client shop: unknown scope "orders:delete"`,
+		},
+		{
+			name:    "key hash not hex",
+			client:  config.APIClient{ID: "shop", APIKeySHA256: strings.Repeat("z", 64), Scopes: []string{ScopeOrdersRead}},
+			wantErr: "API client shop: api_key_sha256 must be 64 hex characters",
+		},
+		{
+			name:    "short key hash",
+			client:  config.APIClient{ID: "shop", APIKeySHA256: keyHash("key-shop")[:32], Scopes: []string{ScopeOrdersRead}},
+			wantErr: "API client shop: api_key_sha256 must be 64 hex characters",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAuthenticator(config.AuthConfig{Clients: []config.APIClient{tt.client}})
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("NewAuthenticator error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	a := testAuthenticator(t)
+	if !a.Enabled() {
+		t.Fatalf("Enabled() = false with configured clients")
+	}
+
+	client, ok := a.Authenticate("key-shop")
+	if !ok || client.ID != "shop" || !client.RequiresSignature() || !client.HasScope(ScopeOrdersWrite) || client.HasScope(ScopeOrdersRead) {
+		t.Errorf("Authenticate(key-shop) = %+v, %v", client, ok)
+	}
+	client, ok = a.Authenticate("key-ops")
+	if !ok || client.ID != "ops" || client.RequiresSignature() {
+		t.Errorf("Authenticate(key-ops) = %+v, %v", client, ok)
+	}
+
+	for _, key := range []string{"", "key-unknown", keyHash("key-shop")} {
+		if client, ok := a.Authenticate(key); ok {
+			t.Errorf("Authenticate(%q) = %+v, want no client", key, client)
+		}
+	}
+
+	empty, err := NewAuthenticator(config.AuthConfig{})
+	if err != nil {
+		t.Fatalf("NewAuthenticator: %v", err)
+	}
+	if empty.Enabled() {
+		t.Errorf("Enabled() = true without clients")
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	a := testAuthenticator(t)
+	client, _ := a.Authenticate("key-shop")
+	now := time.Date(2025, 11, 5, 12, 0, 0, 0, time.UTC)
+	const path = "/api/v1/order?merchant_id=M1"
+	body := []byte(`{"partner_reference_no":"ORDER-001"}`)
+	timestamp := now.Format(time.RFC3339)
+	signature := sign("secret-shop", "POST", path, body, timestamp)
+
+	if err := a.VerifySignature(client, "POST", path, body, timestamp, signature, now); err != nil {
+		t.Fatalf("VerifySignature of a valid request: %v", err)
+	}
+
+	stale := now.Add(-6 * time.Minute).Format(time.RFC3339)
+	future := now.Add(6 * time.Minute).Format(time.RFC3339)
+	tests := []struct {
+		name      string
+		path      string
+		body      []byte
+		timestamp string
+		signature string
+		wantErr   error
+	}{
+		{"missing timestamp", path, body, "", signature, ErrStaleTimestamp},
+		{"malformed timestamp", path, body, "2025-11-05 12:00:00", signature, ErrStaleTimestamp},
+		{"stale timestamp", path, body, stale, sign("secret-shop", "POST", path, body, stale), ErrStaleTimestamp},
+		{"future timestamp", path, body, future, sign("secret-shop", "POST", path, body, future), ErrStaleTimestamp},
+		{"malformed base64", path, body, timestamp, "not base64!", ErrInvalidSignature},
+		{"wrong secret", path, body, timestamp, sign("secret-other", "POST", path, body, timestamp), ErrInvalidSignature},
+		{"tampered body", path, []byte(`{"partner_reference_no":"ORDER-002"}`), timestamp, signature, ErrInvalidSignature},
+		{"tampered query", "/api/v1/order?merchant_id=M2", body, timestamp, signature, ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := a.VerifySignature(client, "POST", tt.path, tt.body, tt.timestamp, tt.signature, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifySignature error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// A timestamp at the edge of the window is accepted
+	edge := now.Add(-5 * time.Minute).Format(time.RFC3339)
+	if err := a.VerifySignature(client, "POST", path, body, edge, sign("secret-shop", "POST", path, body, edge), now); err != nil {
+		t.Errorf("VerifySignature at the edge of the window: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/goccy/go-yaml"
+)
+
+// APIClient is a caller of our API, identified by an API key
+// Only the SHA-256 of the key is configured, so a leaked configuration does not leak keys
+type APIClient struct {
+	ID           string   `yaml:"id"`
+	APIKeySHA256 string   `yaml:"api_key_sha256"` // Hex SHA-256 of the API key sent in X-API-Key
+	HMACSecret   string   `yaml:"hmac_secret"`    // Optional: requests must be signed with this secret
+	Scopes       []string `yaml:"scopes"`         // e.g. orders:write, merchant:read
+}
+
+// apiClientsFile is the layout of an API clients YAML file
+type apiClientsFile struct {
+	Clients []APIClient `yaml:"clients"`
+}
+
+// loadAPIClients reads API clients from a YAML file with a "clients" list
+func loadAPIClients(path string) ([]APIClient, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file apiClientsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return file.Clients, nil
+}
+
+// validateAPIClients returns every missing or invalid API client setting
+// Scope names are checked by the authenticator, which owns them
+func (c *Config) validateAPIClients() []string {
+	var problems []string
+	seenIDs := make(map[string]bool, len(c.Auth.Clients))
+	seenKeys := make(map[string]bool, len(c.Auth.Clients))
+	for i, client := range c.Auth.Clients {
+		name := client.ID
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+			problems = append(problems, fmt.Sprintf("API client %s: id is required", name))
+		} else if seenIDs[client.ID] {
+			problems = append(problems, fmt.Sprintf("API client %s: duplicate id", name))
+		}
+		seenIDs[client.ID] = true
+
+		if key, err := hex.DecodeString(client.APIKeySHA256); err != nil || len(key) != 32 {
+			problems = append(problems, fmt.Sprintf("API client %s: api_key_sha256 must be 64 hex characters", name))
+		} else if seenKeys[string(key)] {
+			problems = append(problems, fmt.Sprintf("API client %s: api_key_sha256 is used by another client", name))
+		} else {
+			seenKeys[string(key)] = true
+		}
+		if len(client.Scopes) == 0 {
+			problems = append(problems, fmt.Sprintf("API client %s: at least one scope is required", name))
+		}
+	}
+	if c.Auth.SignatureMaxSkew <= 0 {
+		problems = append(problems, fmt.Sprintf("API_SIGNATURE_MAX_SKEW must be positive, got %s", c.Auth.SignatureMaxSkew))
+	}
+	return problems
+}
@@ -0,0 +1,691 @@
+package config
+
+import (
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/riyanathariq/dana-enterprise/internal/logging"
+)
+
+// Config is the validated application configuration, loaded once at startup
+type Config struct {
+	Server      ServerConfig          `yaml:"server"`
+	Dana        DanaConfig            `yaml:"dana"`
+	Merchants   []MerchantCredentials `yaml:"merchants"` // Additional merchants, see MerchantsPath
+	Order       OrderConfig           `yaml:"order"`
+	Log         LogConfig             `yaml:"log"`
+	Retry       RetryConfig           `yaml:"retry"`
+	Breaker     BreakerConfig         `yaml:"circuit_breaker"`
+	Reconciler  ReconcilerConfig      `yaml:"reconciler"`
+	Readiness   ReadinessConfig       `yaml:"readiness"`
+	Merchant    MerchantInfoConfig    `yaml:"merchant_info"`
+	PayMethods  PaymentMethodsConfig  `yaml:"payment_methods"`
+	Auth        AuthConfig            `yaml:"auth"`
+	RateLimit   RateLimitConfig       `yaml:"rate_limit"`
+	Idempotency IdempotencyConfig     `yaml:"idempotency"`
+}
+
+// ServerConfig configures the HTTP server and storage
+type ServerConfig struct {
+	Port              string        `yaml:"port"`
+	GinMode           string        `yaml:"gin_mode"`
+	TrustedProxies    string        `yaml:"trusted_proxies"`
+	DatabasePath      string        `yaml:"database_path"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`     // Whole request, including the body
+	WriteTimeout      time.Duration `yaml:"write_timeout"`    // Must cover DANA calls and their retries
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`     // Keep-alive connections
+	MaxHeaderBytes    int           `yaml:"max_header_bytes"` // Request line and headers
+	DrainDelay        time.Duration `yaml:"drain_delay"`      // Time /ready reports not ready before the listener closes
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"` // Time given to in-flight requests on shutdown
+}
+
+// LogConfig configures structured logging
+type LogConfig struct {
+	Level  string `yaml:"level"`  // debug, info, warn or error
+	Format string `yaml:"format"` // json or text
+}
+
+// RetryConfig configures retries of failed DANA calls
+type RetryConfig struct {
+	MaxAttempts    int           `yaml:"max_attempts"` // Including the first attempt, 1 disables retries
+	BaseDelay      time.Duration `yaml:"base_delay"`   // Doubled on every retry, with equal jitter
+	MaxDelay       time.Duration `yaml:"max_delay"`
+	AttemptTimeout time.Duration `yaml:"attempt_timeout"` // Per attempt, every retry gets a fresh timeout
+}
+
+// Budget returns the longest a DANA call can take with every attempt timing out and the longest backoff
+func (r RetryConfig) Budget() time.Duration {
+	budget := time.Duration(r.MaxAttempts) * r.AttemptTimeout
+	delay := r.BaseDelay
+	for retry := 1; retry < r.MaxAttempts; retry++ {
+		budget += min(delay, r.MaxDelay)
+		if delay < r.MaxDelay {
+			delay *= 2
+		}
+	}
+	return budget
+}
+
+// BreakerConfig configures the circuit breaker around DANA calls
+type BreakerConfig struct {
+	FailureThreshold int           `yaml:"failure_threshold"` // Consecutive failures opening the circuit, 0 disables it
+	OpenTimeout      time.Duration `yaml:"open_timeout"`      // Time before a probe call is let through
+}
+
+// ReconcilerConfig configures the background job syncing pending orders with DANA
+type ReconcilerConfig struct {
+	Enabled     bool          `yaml:"enabled"`
+	Interval    time.Duration `yaml:"interval"`    // Time between runs, orders younger than this are skipped
+	Concurrency int           `yaml:"concurrency"` // Orders queried at DANA in parallel
+}
+
+// ReadinessConfig configures the checks behind /ready
+type ReadinessConfig struct {
+	ProbeDana bool          `yaml:"probe_dana"` // Query the default merchant's resources to confirm DANA accepts the credentials
+	ProbeTTL  time.Duration `yaml:"probe_ttl"`  // Time a DANA probe result is reused
+}
+
+// MerchantInfoConfig configures caching of merchant resources queried at DANA
+type MerchantInfoConfig struct {
+	CacheTTL time.Duration `yaml:"cache_ttl"` // Time balances are reused, 0 queries DANA on every request
+}
+
+// PaymentMethodsConfig configures caching of payment methods consulted at DANA
+type PaymentMethodsConfig struct {
+	CacheTTL time.Duration `yaml:"cache_ttl"` // Time a consultation is reused per merchant and amount band, 0 disables the cache
+}
+
+// AuthConfig configures authentication of our own API clients on /api/v1
+// Authentication is enforced once at least one client is configured
+type AuthConfig struct {
+	ClientsPath      string        `yaml:"clients_path"` // YAML file with a "clients" list
+	Clients          []APIClient   `yaml:"clients"`
+	SignatureMaxSkew time.Duration `yaml:"signature_max_skew"` // Accepted X-Timestamp drift of signed requests
+}
+
+// IdempotencyConfig configures replay of idempotent order creation
+type IdempotencyConfig struct {
+	TTL time.Duration `yaml:"ttl"` // Time a stored response is replayed, after which the key can be reused
+}
+
+// Rate limited route groups
+const (
+	RateLimitOrderCreate = "order_create" // POST /api/v1/order and /api/v1/order/custom
+	RateLimitOrder       = "order"        // Other /api/v1/order routes: query, cancel, refund, payment methods; /api/v1/payment
+	RateLimitMerchant    = "merchant"     // /api/v1/merchant
+	RateLimitOrders      = "orders"       // /api/v1/orders, served from the ledger
+	RateLimitRefunds     = "refunds"      // /api/v1/refunds
+)
+
+// RateLimitConfig configures token-bucket rate limits per route group,
+// applied per API client, or per IP for unauthenticated requests
+type RateLimitConfig struct {
+	Enabled     bool          `yaml:"enabled"`
+	OrderCreate RateLimitRule `yaml:"order_create"`
+	Order       RateLimitRule `yaml:"order"`
+	Merchant    RateLimitRule `yaml:"merchant"`
+	Orders      RateLimitRule `yaml:"orders"`
+	Refunds     RateLimitRule `yaml:"refunds"`
+}
+
+// RateLimitRule is the token bucket of a route group
+type RateLimitRule struct {
+	RequestsPerMinute int `yaml:"requests_per_minute"` // Sustained rate, 0 disables the limit
+	Burst             int `yaml:"burst"`               // Requests allowed at once
+}
+
+// Rules returns the rule of every rate limited route group
+func (r RateLimitConfig) Rules() map[string]RateLimitRule {
+	return map[string]RateLimitRule{
+		RateLimitOrderCreate: r.OrderCreate,
+		RateLimitOrder:       r.Order,
+		RateLimitMerchant:    r.Merchant,
+		RateLimitOrders:      r.Orders,
+		RateLimitRefunds:     r.Refunds,
+	}
+}
+
+// DanaConfig holds DANA credentials and API client settings
+type DanaConfig struct {
+	Env            string        `yaml:"env"`
+	Host           string        `yaml:"host"`
+	Scheme         string        `yaml:"scheme"`
+	ClientID       string        `yaml:"client_id"`
+	ClientSecret   string        `yaml:"client_secret"`
+	MerchantID     string        `yaml:"merchant_id"`
+	PrivateKey     string        `yaml:"private_key"`
+	PrivateKeyPath string        `yaml:"private_key_path"`
+	PublicKey      string        `yaml:"public_key"`
+	WebhookMaxSkew time.Duration `yaml:"webhook_max_skew"` // Accepted X-TIMESTAMP drift of DANA webhooks, older ones are rejected as replays
+	PartnerID      string        `yaml:"partner_id"`       // X-PARTNER-ID, defaults to ClientID
+	ChannelID      string        `yaml:"channel_id"`
+	Origin         string        `yaml:"origin"`
+	UserAgent      string        `yaml:"user_agent"`
+	AccessToken    string        `yaml:"access_token"`
+	XDebug         string        `yaml:"x_debug"`
+	Debug          bool          `yaml:"debug"`
+	MerchantsPath  string        `yaml:"merchants_path"` // YAML file or directory with per-merchant credentials
+}
+
+// OrderConfig holds defaults sent in additionalInfo when creating orders
+type OrderConfig struct {
+	MCC               string        `yaml:"mcc"`
+	Title             string        `yaml:"title"` // Defaults to "Order {partnerReferenceNo}"
+	MerchantTransType string        `yaml:"merchant_trans_type"`
+	Buyer             BuyerConfig   `yaml:"buyer"`
+	EnvInfo           EnvInfoConfig `yaml:"env_info"`
+}
+
+// BuyerConfig is the default buyer for custom checkout orders
+type BuyerConfig struct {
+	ExternalUserID   string `yaml:"external_user_id"`
+	UserID           string `yaml:"user_id"`
+	Nickname         string `yaml:"nickname"`
+	ExternalUserType string `yaml:"external_user_type"`
+}
+
+// EnvInfoConfig holds optional envInfo fields for custom checkout orders
+type EnvInfoConfig struct {
+	ClientIP        string `yaml:"client_ip"`
+	SessionID       string `yaml:"session_id"`
+	TokenID         string `yaml:"token_id"`
+	OSType          string `yaml:"os_type"`
+	WebsiteLanguage string `yaml:"website_language"`
+}
+
+// ValidationError lists every missing or invalid setting found while loading
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// BaseURL returns the DANA API base URL for the configured environment
+func (d DanaConfig) BaseURL() string {
+	if d.Env == "production" {
+		return "https://api.dana.id"
+	}
+	// Use custom host if provided, otherwise use default sandbox URL
+	if d.Host != "" {
+		return d.Scheme + "://" + d.Host
+	}
+	return "https://api.sandbox.dana.id"
+}
+
+// IsSandbox reports whether the DANA sandbox environment is configured
+func (d DanaConfig) IsSandbox() bool {
+	return strings.ToLower(d.Env) == "sandbox"
+}
+
+// defaults returns the configuration used when a setting is not provided
+func defaults() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              "3150",
+			GinMode:           "release",
+			DatabasePath:      "dana-enterprise.db",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      2 * time.Minute,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    64 << 10,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Dana: DanaConfig{
+			Env:            "sandbox",
+			Scheme:         "https",
+			ChannelID:      "95221",
+			WebhookMaxSkew: 5 * time.Minute,
+		},
+		Order: OrderConfig{
+			MCC:               "5999", // Miscellaneous
+			MerchantTransType: "SALE",
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Retry: RetryConfig{
+			MaxAttempts:    3,
+			BaseDelay:      200 * time.Millisecond,
+			MaxDelay:       2 * time.Second,
+			AttemptTimeout: 10 * time.Second,
+		},
+		Breaker: BreakerConfig{
+			FailureThreshold: 5,
+			OpenTimeout:      30 * time.Second,
+		},
+		Reconciler: ReconcilerConfig{
+			Enabled:     true,
+			Interval:    time.Minute,
+			Concurrency: 4,
+		},
+		Readiness: ReadinessConfig{
+			ProbeTTL: 5 * time.Minute,
+		},
+		Merchant: MerchantInfoConfig{
+			CacheTTL: 10 * time.Second,
+		},
+		PayMethods: PaymentMethodsConfig{
+			CacheTTL: 5 * time.Minute,
+		},
+		Auth: AuthConfig{
+			SignatureMaxSkew: 5 * time.Minute,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:     true,
+			OrderCreate: RateLimitRule{RequestsPerMinute: 60, Burst: 10},
+			Order:       RateLimitRule{RequestsPerMinute: 300, Burst: 30},
+			Merchant:    RateLimitRule{RequestsPerMinute: 30, Burst: 5},
+			Orders:      RateLimitRule{RequestsPerMinute: 600, Burst: 60},
+			Refunds:     RateLimitRule{RequestsPerMinute: 300, Burst: 30},
+		},
+		Idempotency: IdempotencyConfig{
+			TTL: 24 * time.Hour,
+		},
+	}
+}
+
+// stringVars maps environment variables to configuration fields
+func (c *Config) stringVars() map[string]*string {
+	return map[string]*string{
+		"PORT":                          &c.Server.Port,
+		"GIN_MODE":                      &c.Server.GinMode,
+		"GIN_TRUSTED_PROXIES":           &c.Server.TrustedProxies,
+		"DATABASE_PATH":                 &c.Server.DatabasePath,
+		"DANA_ENV":                      &c.Dana.Env,
+		"DANA_HOST":                     &c.Dana.Host,
+		"DANA_SCHEME":                   &c.Dana.Scheme,
+		"DANA_CLIENT_ID":                &c.Dana.ClientID,
+		"DANA_CLIENT_SECRET":            &c.Dana.ClientSecret,
+		"DANA_MERCHANT_ID":              &c.Dana.MerchantID,
+		"DANA_PRIVATE_KEY":              &c.Dana.PrivateKey,
+		"DANA_PRIVATE_KEY_PATH":         &c.Dana.PrivateKeyPath,
+		"DANA_PUBLIC_KEY":               &c.Dana.PublicKey,
+		"DANA_X_PARTNER_ID":             &c.Dana.PartnerID,
+		"DANA_CHANNEL_ID":               &c.Dana.ChannelID,
+		"DANA_ORIGIN":                   &c.Dana.Origin,
+		"DANA_USER_AGENT":               &c.Dana.UserAgent,
+		"DANA_ACCESS_TOKEN":             &c.Dana.AccessToken,
+		"DANA_X_DEBUG":                  &c.Dana.XDebug,
+		"DANA_MERCHANTS_PATH":           &c.Dana.MerchantsPath,
+		"DANA_MCC":                      &c.Order.MCC,
+		"DANA_ORDER_TITLE":              &c.Order.Title,
+		"DANA_MERCHANT_TRANS_TYPE":      &c.Order.MerchantTransType,
+		"DANA_BUYER_EXTERNAL_USER_ID":   &c.Order.Buyer.ExternalUserID,
+		"DANA_BUYER_USER_ID":            &c.Order.Buyer.UserID,
+		"DANA_BUYER_NICKNAME":           &c.Order.Buyer.Nickname,
+		"DANA_BUYER_EXTERNAL_USER_TYPE": &c.Order.Buyer.ExternalUserType,
+		"DANA_CLIENT_IP":                &c.Order.EnvInfo.ClientIP,
+		"DANA_SESSION_ID":               &c.Order.EnvInfo.SessionID,
+		"DANA_TOKEN_ID":                 &c.Order.EnvInfo.TokenID,
+		"DANA_OS_TYPE":                  &c.Order.EnvInfo.OSType,
+		"DANA_WEBSITE_LANGUAGE":         &c.Order.EnvInfo.WebsiteLanguage,
+		"LOG_LEVEL":                     &c.Log.Level,
+		"LOG_FORMAT":                    &c.Log.Format,
+		"API_CLIENTS_PATH":              &c.Auth.ClientsPath,
+	}
+}
+
+// boolVars maps boolean environment variables to configuration fields
+func (c *Config) boolVars() map[string]*bool {
+	return map[string]*bool{
+		"DANA_DEBUG":           &c.Dana.Debug,
+		"RECONCILER_ENABLED":   &c.Reconciler.Enabled,
+		"READINESS_PROBE_DANA": &c.Readiness.ProbeDana,
+		"RATE_LIMIT_ENABLED":   &c.RateLimit.Enabled,
+	}
+}
+
+// intVars maps integer environment variables to configuration fields
+func (c *Config) intVars() map[string]*int {
+	return map[string]*int{
+		"SERVER_MAX_HEADER_BYTES":        &c.Server.MaxHeaderBytes,
+		"DANA_RETRY_MAX_ATTEMPTS":        &c.Retry.MaxAttempts,
+		"DANA_BREAKER_FAILURE_THRESHOLD": &c.Breaker.FailureThreshold,
+		"RECONCILER_CONCURRENCY":         &c.Reconciler.Concurrency,
+		"RATE_LIMIT_ORDER_CREATE_RPM":    &c.RateLimit.OrderCreate.RequestsPerMinute,
+		"RATE_LIMIT_ORDER_CREATE_BURST":  &c.RateLimit.OrderCreate.Burst,
+		"RATE_LIMIT_ORDER_RPM":           &c.RateLimit.Order.RequestsPerMinute,
+		"RATE_LIMIT_ORDER_BURST":         &c.RateLimit.Order.Burst,
+		"RATE_LIMIT_MERCHANT_RPM":        &c.RateLimit.Merchant.RequestsPerMinute,
+		"RATE_LIMIT_MERCHANT_BURST":      &c.RateLimit.Merchant.Burst,
+		"RATE_LIMIT_ORDERS_RPM":          &c.RateLimit.Orders.RequestsPerMinute,
+		"RATE_LIMIT_ORDERS_BURST":        &c.RateLimit.Orders.Burst,
+		"RATE_LIMIT_REFUNDS_RPM":         &c.RateLimit.Refunds.RequestsPerMinute,
+		"RATE_LIMIT_REFUNDS_BURST":       &c.RateLimit.Refunds.Burst,
+	}
+}
+
+// durationVars maps duration environment variables, such as "500ms" or "30s", to configuration fields
+func (c *Config) durationVars() map[string]*time.Duration {
+	return map[string]*time.Duration{
+		"SERVER_READ_HEADER_TIMEOUT": &c.Server.ReadHeaderTimeout,
+		"SERVER_READ_TIMEOUT":        &c.Server.ReadTimeout,
+		"SERVER_WRITE_TIMEOUT":       &c.Server.WriteTimeout,
+		"SERVER_IDLE_TIMEOUT":        &c.Server.IdleTimeout,
+		"SERVER_DRAIN_DELAY":         &c.Server.DrainDelay,
+		"SERVER_SHUTDOWN_TIMEOUT":    &c.Server.ShutdownTimeout,
+		"DANA_RETRY_BASE_DELAY":      &c.Retry.BaseDelay,
+		"DANA_RETRY_MAX_DELAY":       &c.Retry.MaxDelay,
+		"DANA_RETRY_ATTEMPT_TIMEOUT": &c.Retry.AttemptTimeout,
+		"DANA_BREAKER_OPEN_TIMEOUT":  &c.Breaker.OpenTimeout,
+		"RECONCILER_INTERVAL":        &c.Reconciler.Interval,
+		"READINESS_PROBE_TTL":        &c.Readiness.ProbeTTL,
+		"MERCHANT_INFO_CACHE_TTL":    &c.Merchant.CacheTTL,
+		"PAYMENT_METHOD_CACHE_TTL":   &c.PayMethods.CacheTTL,
+		"API_SIGNATURE_MAX_SKEW":     &c.Auth.SignatureMaxSkew,
+		"DANA_WEBHOOK_MAX_SKEW":      &c.Dana.WebhookMaxSkew,
+		"IDEMPOTENCY_KEY_TTL":        &c.Idempotency.TTL,
+	}
+}
+
+// Load builds the configuration from defaults, an optional YAML file, environment variables and flags,
+// in increasing order of precedence
+// The YAML file is given by -config or CONFIG_FILE. All problems are reported at once as *ValidationError
+func Load(args []string) (*Config, error) {
+	cfg := defaults()
+	var problems []string
+
+	fs := flag.NewFlagSet("dana-enterprise", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path to YAML configuration file")
+	port := fs.String("port", "", "HTTP port (PORT)")
+	env := fs.String("env", "", "DANA environment: sandbox or production (DANA_ENV)")
+	databasePath := fs.String("database-path", "", "SQLite order ledger path (DATABASE_PATH)")
+	debug := fs.Bool("debug", false, "log redacted DANA requests and responses (DANA_DEBUG)")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// YAML file
+	if *configFile != "" {
+		data, err := os.ReadFile(*configFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", *configFile, err)
+		}
+	}
+
+	// Environment variables
+	for key, field := range cfg.stringVars() {
+		if value := os.Getenv(key); value != "" {
+			*field = value
+		}
+	}
+	for key, field := range cfg.boolVars() {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s must be a boolean, got %q", key, value))
+			continue
+		}
+		*field = parsed
+	}
+	for key, field := range cfg.intVars() {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s must be an integer, got %q", key, value))
+			continue
+		}
+		*field = parsed
+	}
+	for key, field := range cfg.durationVars() {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s must be a duration such as 500ms or 30s, got %q", key, value))
+			continue
+		}
+		*field = parsed
+	}
+
+	// Flags explicitly set on the command line
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Server.Port = *port
+		case "env":
+			cfg.Dana.Env = *env
+		case "database-path":
+			cfg.Server.DatabasePath = *databasePath
+		case "debug":
+			cfg.Dana.Debug = *debug
+		}
+	})
+
+	// DANA request and response bodies are logged at debug level
+	if cfg.Dana.Debug {
+		cfg.Log.Level = "debug"
+	}
+
+	// Private key may be provided as a file instead of inline
+	if cfg.Dana.PrivateKey == "" && cfg.Dana.PrivateKeyPath != "" {
+		data, err := os.ReadFile(cfg.Dana.PrivateKeyPath)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("DANA_PRIVATE_KEY_PATH cannot be read: %v", err))
+		} else {
+			cfg.Dana.PrivateKey = string(data)
+		}
+	}
+
+	// Per-merchant credentials
+	if cfg.Dana.MerchantsPath != "" {
+		merchants, err := loadMerchants(cfg.Dana.MerchantsPath)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("DANA_MERCHANTS_PATH cannot be loaded: %v", err))
+		}
+		cfg.Merchants = append(cfg.Merchants, merchants...)
+	}
+	problems = append(problems, cfg.resolveMerchantKeys()...)
+
+	// API clients
+	if cfg.Auth.ClientsPath != "" {
+		clients, err := loadAPIClients(cfg.Auth.ClientsPath)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("API_CLIENTS_PATH cannot be loaded: %v", err))
+		}
+		cfg.Auth.Clients = append(cfg.Auth.Clients, clients...)
+	}
+
+	// X_PARTNER_ID should be Client ID for authentication, not Merchant ID
+	if cfg.Dana.PartnerID == "" {
+		cfg.Dana.PartnerID = cfg.Dana.ClientID
+	}
+
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+var (
+	portPattern = regexp.MustCompile(`^[0-9]{1,5}$`)
+	mccPattern  = regexp.MustCompile(`^[0-9]{4}$`)
+)
+
+// validate returns every missing or invalid setting
+func (c *Config) validate() []string {
+	var problems []string
+
+	if !portPattern.MatchString(c.Server.Port) {
+		problems = append(problems, fmt.Sprintf("PORT must be a number, got %q", c.Server.Port))
+	} else if port, _ := strconv.Atoi(c.Server.Port); port < 1 || port > 65535 {
+		problems = append(problems, fmt.Sprintf("PORT must be between 1 and 65535, got %d", port))
+	}
+	switch c.Server.GinMode {
+	case "debug", "release", "test":
+	default:
+		problems = append(problems, fmt.Sprintf("GIN_MODE must be debug, release or test, got %q", c.Server.GinMode))
+	}
+	if c.Server.DatabasePath == "" {
+		problems = append(problems, "DATABASE_PATH is required")
+	}
+	serverTimeouts := []struct {
+		key     string
+		timeout time.Duration
+	}{
+		{"SERVER_READ_HEADER_TIMEOUT", c.Server.ReadHeaderTimeout},
+		{"SERVER_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout},
+		{"SERVER_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+	}
+	for _, t := range serverTimeouts {
+		if t.timeout <= 0 {
+			problems = append(problems, fmt.Sprintf("%s must be positive, got %s", t.key, t.timeout))
+		}
+	}
+	if c.Server.DrainDelay < 0 {
+		problems = append(problems, fmt.Sprintf("SERVER_DRAIN_DELAY must not be negative, got %s", c.Server.DrainDelay))
+	}
+	if c.Server.MaxHeaderBytes < 4<<10 {
+		problems = append(problems, fmt.Sprintf("SERVER_MAX_HEADER_BYTES must be at least 4096, got %d", c.Server.MaxHeaderBytes))
+	}
+	if _, ok := logging.ParseLevel(c.Log.Level); !ok {
+		problems = append(problems, fmt.Sprintf("LOG_LEVEL must be debug, info, warn or error, got %q", c.Log.Level))
+	}
+	switch c.Log.Format {
+	case "json", "text":
+	default:
+		problems = append(problems, fmt.Sprintf("LOG_FORMAT must be json or text, got %q", c.Log.Format))
+	}
+
+	switch c.Dana.Env {
+	case "sandbox", "production":
+	default:
+		problems = append(problems, fmt.Sprintf("DANA_ENV must be sandbox or production, got %q", c.Dana.Env))
+	}
+	switch c.Dana.Scheme {
+	case "http", "https":
+	default:
+		problems = append(problems, fmt.Sprintf("DANA_SCHEME must be http or https, got %q", c.Dana.Scheme))
+	}
+	// Default credentials are optional when merchants are configured
+	if len(c.Merchants) == 0 || c.Dana.HasCredentials() {
+		if c.Dana.ClientID == "" {
+			problems = append(problems, "DANA_CLIENT_ID is required")
+		}
+		if c.Dana.ClientSecret == "" {
+			problems = append(problems, "DANA_CLIENT_SECRET is required")
+		}
+		if c.Dana.PrivateKey == "" {
+			if c.Dana.PrivateKeyPath == "" {
+				problems = append(problems, "DANA_PRIVATE_KEY or DANA_PRIVATE_KEY_PATH is required")
+			}
+		} else if err := checkPEM(c.Dana.PrivateKey); err != nil {
+			problems = append(problems, fmt.Sprintf("DANA_PRIVATE_KEY is invalid: %v", err))
+		}
+	}
+	if c.Dana.PublicKey != "" {
+		if err := checkPEM(c.Dana.PublicKey); err != nil {
+			problems = append(problems, fmt.Sprintf("DANA_PUBLIC_KEY is invalid: %v", err))
+		}
+	}
+	if c.Dana.ChannelID == "" {
+		problems = append(problems, "DANA_CHANNEL_ID is required")
+	}
+	if c.Dana.WebhookMaxSkew <= 0 {
+		problems = append(problems, fmt.Sprintf("DANA_WEBHOOK_MAX_SKEW must be positive, got %s", c.Dana.WebhookMaxSkew))
+	}
+
+	problems = append(problems, c.validateMerchants()...)
+	problems = append(problems, c.validateAPIClients()...)
+
+	if c.Retry.MaxAttempts < 1 {
+		problems = append(problems, fmt.Sprintf("DANA_RETRY_MAX_ATTEMPTS must be at least 1, got %d", c.Retry.MaxAttempts))
+	}
+	if c.Retry.BaseDelay < 0 || c.Retry.MaxDelay < c.Retry.BaseDelay {
+		problems = append(problems, fmt.Sprintf("DANA_RETRY_BASE_DELAY (%s) and DANA_RETRY_MAX_DELAY (%s) must satisfy 0 <= base <= max", c.Retry.BaseDelay, c.Retry.MaxDelay))
+	}
+	if c.Retry.AttemptTimeout <= 0 {
+		problems = append(problems, fmt.Sprintf("DANA_RETRY_ATTEMPT_TIMEOUT must be positive, got %s", c.Retry.AttemptTimeout))
+	} else if budget := c.Retry.Budget(); c.Server.WriteTimeout > 0 && budget > c.Server.WriteTimeout {
+		problems = append(problems, fmt.Sprintf("DANA calls with retries can take up to %s (DANA_RETRY_MAX_ATTEMPTS x DANA_RETRY_ATTEMPT_TIMEOUT plus backoff), more than SERVER_WRITE_TIMEOUT (%s)", budget, c.Server.WriteTimeout))
+	}
+	if c.Breaker.FailureThreshold < 0 {
+		problems = append(problems, fmt.Sprintf("DANA_BREAKER_FAILURE_THRESHOLD must not be negative, got %d", c.Breaker.FailureThreshold))
+	}
+	if c.Breaker.FailureThreshold > 0 && c.Breaker.OpenTimeout <= 0 {
+		problems = append(problems, "DANA_BREAKER_OPEN_TIMEOUT must be positive")
+	}
+	if c.Reconciler.Enabled {
+		if c.Reconciler.Interval < time.Second {
+			problems = append(problems, fmt.Sprintf("RECONCILER_INTERVAL must be at least 1s, got %s", c.Reconciler.Interval))
+		}
+		if c.Reconciler.Concurrency < 1 {
+			problems = append(problems, fmt.Sprintf("RECONCILER_CONCURRENCY must be at least 1, got %d", c.Reconciler.Concurrency))
+		}
+	}
+	if c.RateLimit.Enabled {
+		rules := c.RateLimit.Rules()
+		for _, group := range slices.Sorted(maps.Keys(rules)) {
+			rule := rules[group]
+			key := "RATE_LIMIT_" + strings.ToUpper(group)
+			if rule.RequestsPerMinute < 0 {
+				problems = append(problems, fmt.Sprintf("%s_RPM must not be negative, got %d", key, rule.RequestsPerMinute))
+			}
+			if rule.RequestsPerMinute > 0 && rule.Burst < 1 {
+				problems = append(problems, fmt.Sprintf("%s_BURST must be at least 1, got %d", key, rule.Burst))
+			}
+		}
+	}
+	if c.Readiness.ProbeDana && c.Readiness.ProbeTTL < time.Second {
+		problems = append(problems, fmt.Sprintf("READINESS_PROBE_TTL must be at least 1s, got %s", c.Readiness.ProbeTTL))
+	}
+	if c.Merchant.CacheTTL < 0 {
+		problems = append(problems, fmt.Sprintf("MERCHANT_INFO_CACHE_TTL must not be negative, got %s", c.Merchant.CacheTTL))
+	}
+	if c.PayMethods.CacheTTL < 0 {
+		problems = append(problems, fmt.Sprintf("PAYMENT_METHOD_CACHE_TTL must not be negative, got %s", c.PayMethods.CacheTTL))
+	}
+	if c.Idempotency.TTL <= 0 {
+		problems = append(problems, fmt.Sprintf("IDEMPOTENCY_KEY_TTL must be positive, got %s", c.Idempotency.TTL))
+	}
+
+	if !mccPattern.MatchString(c.Order.MCC) {
+		problems = append(problems, fmt.Sprintf("DANA_MCC must be a 4-digit merchant category code, got %q", c.Order.MCC))
+	}
+	if c.Order.MerchantTransType == "" {
+		problems = append(problems, "DANA_MERCHANT_TRANS_TYPE is required")
+	}
+
+	return problems
+}
+
+// checkPEM checks that a key contains a decodable PEM block, accepting "\n" literals as newlines
+func checkPEM(key string) error {
+	key = strings.ReplaceAll(key, "\\n", "\n")
+	if !strings.Contains(key, "-----BEGIN") {
+		return errors.New("missing PEM headers")
+	}
+	if block, _ := pem.Decode([]byte(key)); block == nil {
+		return errors.New("failed to parse PEM block")
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// MerchantCredentials holds the DANA credentials of one onboarded merchant
+// Empty optional fields fall back to the DANA_* settings
+type MerchantCredentials struct {
+	MerchantID     string `yaml:"merchant_id"`
+	ClientID       string `yaml:"client_id"`
+	ClientSecret   string `yaml:"client_secret"`
+	PrivateKey     string `yaml:"private_key"`
+	PrivateKeyPath string `yaml:"private_key_path"`
+	PublicKey      string `yaml:"public_key"` // Optional: DANA public key for this merchant's webhooks
+	PartnerID      string `yaml:"partner_id"` // Optional: X-PARTNER-ID, defaults to ClientID
+	ChannelID      string `yaml:"channel_id"` // Optional
+	Origin         string `yaml:"origin"`     // Optional
+}
+
+// merchantsFile is the layout of a merchants YAML file
+type merchantsFile struct {
+	Merchants []MerchantCredentials `yaml:"merchants"`
+}
+
+// ForMerchant returns the DANA configuration of a merchant, sharing environment settings with d
+func (d DanaConfig) ForMerchant(m MerchantCredentials) DanaConfig {
+	merchant := d
+	merchant.MerchantID = m.MerchantID
+	merchant.ClientID = m.ClientID
+	merchant.ClientSecret = m.ClientSecret
+	merchant.PrivateKey = m.PrivateKey
+	merchant.PrivateKeyPath = m.PrivateKeyPath
+	merchant.PartnerID = m.PartnerID
+	if merchant.PartnerID == "" {
+		merchant.PartnerID = m.ClientID
+	}
+	if m.PublicKey != "" {
+		merchant.PublicKey = m.PublicKey
+	}
+	if m.ChannelID != "" {
+		merchant.ChannelID = m.ChannelID
+	}
+	if m.Origin != "" {
+		merchant.Origin = m.Origin
+	}
+	// Access tokens are issued per client
+	merchant.AccessToken = ""
+	return merchant
+}
+
+// HasCredentials reports whether default DANA_* credentials are configured
+func (d DanaConfig) HasCredentials() bool {
+	return d.ClientID != "" || d.ClientSecret != "" || d.PrivateKey != "" || d.PrivateKeyPath != ""
+}
+
+// loadMerchants reads merchant credentials from a YAML file with a "merchants" list,
+// or from a directory holding one merchant per *.yaml / *.yml file
+func loadMerchants(path string) ([]MerchantCredentials, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var file merchantsFile
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		return file.Merchants, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	merchants := make([]MerchantCredentials, 0, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(path, name))
+		if err != nil {
+			return nil, err
+		}
+		var merchant MerchantCredentials
+		if err := yaml.Unmarshal(data, &merchant); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(path, name), err)
+		}
+		merchants = append(merchants, merchant)
+	}
+	return merchants, nil
+}
+
+// resolveMerchantKeys reads private keys given as files, returning problems for unreadable files
+func (c *Config) resolveMerchantKeys() []string {
+	var problems []string
+	for i := range c.Merchants {
+		m := &c.Merchants[i]
+		if m.PrivateKey != "" || m.PrivateKeyPath == "" {
+			continue
+		}
+		data, err := os.ReadFile(m.PrivateKeyPath)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("merchant %s: private_key_path cannot be read: %v", m.MerchantID, err))
+			continue
+		}
+		m.PrivateKey = string(data)
+	}
+	return problems
+}
+
+// validateMerchants returns every missing or invalid merchant credential
+func (c *Config) validateMerchants() []string {
+	var problems []string
+	seen := make(map[string]bool, len(c.Merchants))
+	for i, m := range c.Merchants {
+		name := m.MerchantID
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+			problems = append(problems, fmt.Sprintf("merchant %s: merchant_id is required", name))
+		} else if seen[m.MerchantID] {
+			problems = append(problems, fmt.Sprintf("merchant %s: duplicate merchant_id", name))
+		}
+		seen[m.MerchantID] = true
+
+		if m.ClientID == "" {
+			problems = append(problems, fmt.Sprintf("merchant %s: client_id is required", name))
+		}
+		if m.ClientSecret == "" {
+			problems = append(problems, fmt.Sprintf("merchant %s: client_secret is required", name))
+		}
+		if m.PrivateKey == "" {
+			if m.PrivateKeyPath == "" {
+				problems = append(problems, fmt.Sprintf("merchant %s: private_key or private_key_path is required", name))
+			}
+		} else if err := checkPEM(m.PrivateKey); err != nil {
+			problems = append(problems, fmt.Sprintf("merchant %s: private_key is invalid: %v", name, err))
+		}
+		if m.PublicKey != "" {
+			if err := checkPEM(m.PublicKey); err != nil {
+				problems = append(problems, fmt.Sprintf("merchant %s: public_key is invalid: %v", name, err))
+			}
+		}
+	}
+
+	// Without default credentials the default merchant must come from the registry
+	if !c.Dana.HasCredentials() && c.Dana.MerchantID != "" && !seen[c.Dana.MerchantID] {
+		problems = append(problems, fmt.Sprintf("DANA_MERCHANT_ID %s has no credentials in DANA_MERCHANTS_PATH", c.Dana.MerchantID))
+	}
+	return problems
+}
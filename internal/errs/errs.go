@@ -0,0 +1,34 @@
+// Package errs holds the sentinel errors shared by the repository, the services and the mapper,
+// so that mapping an error to a response does not depend on the package that returned it.
+package errs
+
+import "errors"
+
+// Order ledger
+var (
+	// ErrFinalStatus is returned when an update would move an order out of a terminal status, see repository.CanTransition
+	ErrFinalStatus = errors.New("order status is final")
+)
+
+// Orders
+var (
+	// ErrAmountMismatch is returned when the transAmounts of a custom checkout order do not add up to its amount
+	ErrAmountMismatch = errors.New("total transAmount does not match amount")
+	// ErrMalformedCreateOrderResponse is returned when DANA created an order but its response cannot be read
+	// The order is recorded in the ledger all the same, so it can be queried or cancelled
+	ErrMalformedCreateOrderResponse = errors.New("malformed create order response")
+	// ErrInvalidPaymentMethodQuery is returned when payment method consultation parameters are malformed
+	ErrInvalidPaymentMethodQuery = errors.New("invalid payment method query")
+	// ErrInvalidListFilter is returned when list filters are malformed or inconsistent
+	ErrInvalidListFilter = errors.New("invalid order filter")
+)
+
+// Refunds
+var (
+	// ErrRefundNotFound is returned when no refund exists for a partner refund number
+	ErrRefundNotFound = errors.New("refund not found")
+	// ErrDuplicateRefund is returned when a partner refund number has already been used
+	ErrDuplicateRefund = errors.New("partnerRefundNo already exists")
+	// ErrRefundExceedsAmount is returned when cumulative refunds would exceed the order amount
+	ErrRefundExceedsAmount = errors.New("refund amount exceeds refundable amount")
+)
@@ -1,36 +1,109 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
-	"os"
+	"strconv"
+	"time"
 
 	"github.com/dana-id/dana-go/payment_gateway/v1"
 	"github.com/gin-gonic/gin"
+	"github.com/riyanathariq/dana-enterprise/internal/config"
 	"github.com/riyanathariq/dana-enterprise/internal/mapper"
 	"github.com/riyanathariq/dana-enterprise/internal/model"
+	"github.com/riyanathariq/dana-enterprise/internal/money"
+	danaSDK "github.com/riyanathariq/dana-enterprise/internal/sdk/dana"
 	"github.com/riyanathariq/dana-enterprise/internal/service/dana/merchant"
 	"github.com/riyanathariq/dana-enterprise/internal/service/dana/order"
+	"github.com/riyanathariq/dana-enterprise/internal/validation"
 )
 
+// CacheHeader reports whether a response was served from the cache: HIT, MISS or BYPASS (?fresh=true)
+const CacheHeader = "X-Cache"
+
 type DanaHandler struct {
+	cfg             *config.Config
+	merchants       *danaSDK.Registry
 	merchantService *merchant.Service
 	orderService    *order.Service
 }
 
-func NewDanaHandler() *DanaHandler {
+func NewDanaHandler(cfg *config.Config, merchants *danaSDK.Registry, merchantService *merchant.Service, orderService *order.Service) *DanaHandler {
 	return &DanaHandler{
-		merchantService: merchant.NewService(),
-		orderService:    order.NewService(),
+		cfg:             cfg,
+		merchants:       merchants,
+		merchantService: merchantService,
+		orderService:    orderService,
+	}
+}
+
+// respondDanaError writes the response for DANA error responses and errors known to the DANA layer
+// It returns false for any other error, which the caller reports as 500
+func respondDanaError(c *gin.Context, err error) bool {
+	if danaErr, ok := danaSDK.AsDanaError(err); ok {
+		status, response := mapper.MapDanaError(danaErr)
+		c.Error(err)
+		c.JSON(status, response)
+		return true
+	}
+
+	switch {
+	case errors.Is(err, danaSDK.ErrUnknownMerchant):
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "UNKNOWN_MERCHANT",
+			Details: "No DANA credentials are registered for this merchant",
+		})
+	case errors.Is(err, danaSDK.ErrCircuitOpen):
+		c.Error(err)
+		c.JSON(http.StatusServiceUnavailable, model.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "DANA_UNAVAILABLE",
+			Details: "DANA is failing, requests are paused until the circuit breaker closes",
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+// respondServiceError writes the response for errors known to the services, see mapper.MapServiceError
+// It returns false for any other error, which the caller reports as 500
+func respondServiceError(c *gin.Context, err error) bool {
+	status, response, ok := mapper.MapServiceError(err)
+	if !ok {
+		return false
 	}
+	if status >= http.StatusInternalServerError {
+		c.Error(err)
+	}
+	c.JSON(status, response)
+	return true
+}
+
+// respondValidationErrors rejects a request with every invalid field listed
+func respondValidationErrors(c *gin.Context, errs []model.FieldError) {
+	c.JSON(http.StatusUnprocessableEntity, model.ErrorResponse{
+		Success: false,
+		Error:   "request validation failed",
+		Code:    "VALIDATION_ERROR",
+		Details: fmt.Sprintf("%d invalid field(s), see errors", len(errs)),
+		Errors:  errs,
+	})
 }
 
 // GetMerchantInfo godoc
 // @Summary Get merchant information
-// @Description Get merchant resource information including balances
+// @Description Get merchant resource information including balances, cached for MERCHANT_INFO_CACHE_TTL
 // @Tags merchant
 // @Accept json
 // @Produce json
-// @Param merchant_id path string false "Merchant ID (optional, uses env if not provided)"
+// @Param merchant_id path string false "Merchant ID (optional, uses DANA_MERCHANT_ID if not provided)"
+// @Param fresh query bool false "Bypass the cache and query DANA"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
@@ -39,7 +112,9 @@ func NewDanaHandler() *DanaHandler {
 func (h *DanaHandler) GetMerchantInfo(c *gin.Context) {
 	merchantID := c.Param("merchant_id")
 	if merchantID == "" {
-		merchantID = os.Getenv("DANA_MERCHANT_ID")
+		if defaultMerchant := h.merchants.Default(); defaultMerchant != nil {
+			merchantID = defaultMerchant.ID
+		}
 	}
 
 	if merchantID == "" {
@@ -47,13 +122,18 @@ func (h *DanaHandler) GetMerchantInfo(c *gin.Context) {
 			Success: false,
 			Error:   "merchant_id is required",
 			Code:    "VALIDATION_ERROR",
-			Details: "Merchant ID must be provided either as path parameter or in environment variable DANA_MERCHANT_ID",
+			Details: "Merchant ID must be provided either as path parameter or configured as DANA_MERCHANT_ID",
 		})
 		return
 	}
 
-	result, err := h.merchantService.GetMerchantInfo(c.Request.Context(), merchantID)
+	fresh := c.Query("fresh") == "true"
+	info, err := h.merchantService.GetMerchantInfo(c.Request.Context(), merchantID, fresh)
 	if err != nil {
+		if respondDanaError(c, err) {
+			return
+		}
+		c.Error(err)
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Error:   err.Error(),
@@ -64,10 +144,33 @@ func (h *DanaHandler) GetMerchantInfo(c *gin.Context) {
 	}
 
 	// Map to clean response format
-	response := mapper.MapMerchantResourceResponse(merchantID, result)
+	response := mapper.MapMerchantResourceResponse(merchantID, info.Resource)
+	if response.Meta != nil {
+		response.Meta.CachedAt = info.FetchedAt.Format(time.RFC3339)
+	}
+	setMerchantInfoCacheHeaders(c, info, fresh, h.cfg.Merchant.CacheTTL)
 	c.JSON(http.StatusOK, response)
 }
 
+// setMerchantInfoCacheHeaders tells clients whether balances came from the cache and how long they may reuse them
+func setMerchantInfoCacheHeaders(c *gin.Context, info *merchant.Info, fresh bool, ttl time.Duration) {
+	switch {
+	case info.Cached:
+		c.Header(CacheHeader, "HIT")
+	case fresh:
+		c.Header(CacheHeader, "BYPASS")
+	default:
+		c.Header(CacheHeader, "MISS")
+	}
+
+	maxAge := int(time.Until(info.ExpiresAt(ttl)).Seconds())
+	if maxAge <= 0 {
+		c.Header("Cache-Control", "no-store")
+		return
+	}
+	c.Header("Cache-Control", "private, max-age="+strconv.Itoa(maxAge))
+}
+
 // CreateOrder godoc
 // @Summary Create a new order
 // @Description Create a new payment order in DANA Payment Gateway
@@ -75,9 +178,11 @@ func (h *DanaHandler) GetMerchantInfo(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body model.CreateOrderRequest true "Create Order Request"
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {object} model.CreateOrderResponse
 // @Failure 400 {object} model.ErrorResponse
+// @Failure 422 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
+// @Failure 502 {object} model.ErrorResponse
 // @Router /api/v1/order [post]
 func (h *DanaHandler) CreateOrder(c *gin.Context) {
 	var req model.CreateOrderRequest
@@ -91,6 +196,11 @@ func (h *DanaHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
+	if errs := validation.ValidateCreateOrder(&req, time.Now()); len(errs) > 0 {
+		respondValidationErrors(c, errs)
+		return
+	}
+
 	// Convert request model to service params
 	params := order.CreateOrderRequestParams{
 		PartnerReferenceNo: req.PartnerReferenceNo,
@@ -111,8 +221,8 @@ func (h *DanaHandler) CreateOrder(c *gin.Context) {
 		params.PayOptionDetails = make([]payment_gateway.PayOptionDetail, len(req.PayOptionDetails))
 		for i, pod := range req.PayOptionDetails {
 			payOptionDetail := payment_gateway.PayOptionDetail{
-				PayMethod: pod.PayMethod,
-				PayOption: pod.PayOption,
+				PayMethod: string(pod.PayMethod),
+				PayOption: string(pod.PayOption),
 				TransAmount: payment_gateway.Money{
 					Value:    pod.TransAmount.Value,
 					Currency: pod.TransAmount.Currency,
@@ -144,7 +254,7 @@ func (h *DanaHandler) CreateOrder(c *gin.Context) {
 	}
 
 	// Auto-detect: if PayOptionDetails provided, use custom checkout; otherwise use hosted checkout
-	var result *payment_gateway.CreateOrderResponse
+	var result *order.CreatedOrder
 	var err error
 
 	if len(params.PayOptionDetails) > 0 {
@@ -156,6 +266,10 @@ func (h *DanaHandler) CreateOrder(c *gin.Context) {
 	}
 
 	if err != nil {
+		if respondDanaError(c, err) || respondServiceError(c, err) {
+			return
+		}
+		c.Error(err)
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Error:   err.Error(),
@@ -165,11 +279,7 @@ func (h *DanaHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Order created successfully",
-		"data":    result,
-	})
+	c.JSON(http.StatusOK, mapper.MapCreateOrderResponse(result, "Order created successfully"))
 }
 
 // CreateOrderCustomCheckout godoc
@@ -179,9 +289,11 @@ func (h *DanaHandler) CreateOrder(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body model.CreateOrderRequest true "Create Order Request (requires pay_option_details)"
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {object} model.CreateOrderResponse
 // @Failure 400 {object} model.ErrorResponse
+// @Failure 422 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
+// @Failure 502 {object} model.ErrorResponse
 // @Router /api/v1/order/custom [post]
 func (h *DanaHandler) CreateOrderCustomCheckout(c *gin.Context) {
 	var req model.CreateOrderRequest
@@ -206,6 +318,11 @@ func (h *DanaHandler) CreateOrderCustomCheckout(c *gin.Context) {
 		return
 	}
 
+	if errs := validation.ValidateCreateOrder(&req, time.Now()); len(errs) > 0 {
+		respondValidationErrors(c, errs)
+		return
+	}
+
 	// Convert request model to service params
 	params := order.CreateOrderRequestParams{
 		PartnerReferenceNo: req.PartnerReferenceNo,
@@ -225,8 +342,8 @@ func (h *DanaHandler) CreateOrderCustomCheckout(c *gin.Context) {
 	// Convert PayOptionDetails
 	for i, pod := range req.PayOptionDetails {
 		payOptionDetail := payment_gateway.PayOptionDetail{
-			PayMethod: pod.PayMethod,
-			PayOption: pod.PayOption,
+			PayMethod: string(pod.PayMethod),
+			PayOption: string(pod.PayOption),
 			TransAmount: payment_gateway.Money{
 				Value:    pod.TransAmount.Value,
 				Currency: pod.TransAmount.Currency,
@@ -259,6 +376,10 @@ func (h *DanaHandler) CreateOrderCustomCheckout(c *gin.Context) {
 	// Create order using custom checkout
 	result, err := h.orderService.CreateOrderCustomCheckout(c.Request.Context(), params)
 	if err != nil {
+		if respondDanaError(c, err) || respondServiceError(c, err) {
+			return
+		}
+		c.Error(err)
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Error:   err.Error(),
@@ -268,24 +389,49 @@ func (h *DanaHandler) CreateOrderCustomCheckout(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Order created successfully (Custom Checkout)",
-		"data":    result,
-	})
+	c.JSON(http.StatusOK, mapper.MapCreateOrderResponse(result, "Order created successfully (Custom Checkout)"))
 }
 
 // GetPaymentMethod godoc
-// @Summary Get payment method
-// @Description Get payment method from DANA Payment Gateway
+// @Summary Get payment methods
+// @Description Consult DANA for the pay methods and pay options available to an order, with fees and limits
 // @Tags payment
 // @Accept json
 // @Produce json
-// @Success 200 {object} map[string]interface{}
-// @Router /api/v1/payment/method [get]
+// @Param amount query string true "Order amount (e.g. 10000.00)"
+// @Param currency query string false "Currency (default IDR)"
+// @Param merchant_id query string false "Merchant ID (optional, uses DANA_MERCHANT_ID if not provided)"
+// @Param terminal_type query string false "APP, WEB, WAP or SYSTEM (default WEB)"
+// @Param external_user_id query string false "Buyer ID on partner system"
+// @Param user_id query string false "Buyer DANA user ID"
+// @Success 200 {object} model.PaymentMethodListResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/order/payment/method [get]
 func (h *DanaHandler) GetPaymentMethod(c *gin.Context) {
-	result, err := h.orderService.GetPaymentMethod(c.Request.Context())
+	amount, err := money.Parse(c.Query("amount"), c.DefaultQuery("currency", money.IDR))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "VALIDATION_ERROR",
+			Details: "amount is required, as an IDR value with at most 2 decimal places, e.g. amount=10000.00",
+		})
+		return
+	}
+
+	result, err := h.orderService.GetPaymentMethod(c.Request.Context(), order.PaymentMethodParams{
+		MerchantID:     c.Query("merchant_id"),
+		Amount:         amount,
+		TerminalType:   c.Query("terminal_type"),
+		ExternalUserID: c.Query("external_user_id"),
+		UserID:         c.Query("user_id"),
+	})
 	if err != nil {
+		if respondDanaError(c, err) || respondServiceError(c, err) {
+			return
+		}
+		c.Error(err)
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Error:   err.Error(),
@@ -295,11 +441,12 @@ func (h *DanaHandler) GetPaymentMethod(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Payment method retrieved successfully",
-		"data":    result,
-	})
+	if result.Cached {
+		c.Header(CacheHeader, "HIT")
+	} else {
+		c.Header(CacheHeader, "MISS")
+	}
+	c.JSON(http.StatusOK, mapper.MapPaymentMethods(result))
 }
 
 // GetOrder godoc
@@ -327,6 +474,10 @@ func (h *DanaHandler) GetOrder(c *gin.Context) {
 
 	result, err := h.orderService.GetOrder(c.Request.Context(), partnerReferenceNo)
 	if err != nil {
+		if respondDanaError(c, err) {
+			return
+		}
+		c.Error(err)
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Success: false,
 			Error:   err.Error(),
@@ -343,17 +494,72 @@ func (h *DanaHandler) GetOrder(c *gin.Context) {
 	})
 }
 
-// HealthCheck godoc
-// @Summary Health check endpoint
-// @Description Check if the API is running
-// @Tags health
+// CancelOrder godoc
+// @Summary Cancel an unpaid order
+// @Description Void an unpaid order in DANA Payment Gateway by partner reference number
+// @Tags order
 // @Accept json
 // @Produce json
+// @Param partner_reference_no path string true "Partner Reference Number"
+// @Param request body model.CancelOrderRequest true "Cancel Order Request"
 // @Success 200 {object} map[string]interface{}
-// @Router /health [get]
-func (h *DanaHandler) HealthCheck(c *gin.Context) {
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/order/{partner_reference_no}/cancel [post]
+func (h *DanaHandler) CancelOrder(c *gin.Context) {
+	partnerReferenceNo := c.Param("partner_reference_no")
+	if partnerReferenceNo == "" {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Error:   "partner_reference_no is required",
+			Code:    "VALIDATION_ERROR",
+			Details: "Partner reference number must be provided as path parameter",
+		})
+		return
+	}
+
+	var req model.CancelOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "VALIDATION_ERROR",
+			Details: "Invalid request body",
+		})
+		return
+	}
+
+	// Convert request model to service params
+	params := order.CancelOrderRequestParams{
+		PartnerReferenceNo: partnerReferenceNo,
+		MerchantID:         req.MerchantID,
+		Reason:             &req.Reason,
+	}
+	if req.Amount != nil {
+		params.Amount = &payment_gateway.Money{
+			Value:    req.Amount.Value,
+			Currency: req.Amount.Currency,
+		}
+	}
+
+	result, err := h.orderService.CancelOrder(c.Request.Context(), params)
+	if err != nil {
+		if respondDanaError(c, err) || respondServiceError(c, err) {
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "CANCEL_ORDER_ERROR",
+			Details: "Failed to cancel order in Dana API",
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"status":  "ok",
-		"message": "Dana Enterprise API is running",
+		"success": true,
+		"message": "Order cancelled successfully",
+		"data":    result,
 	})
 }
@@ -0,0 +1,469 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riyanathariq/dana-enterprise/internal/auth"
+	"github.com/riyanathariq/dana-enterprise/internal/config"
+	"github.com/riyanathariq/dana-enterprise/internal/handler"
+	"github.com/riyanathariq/dana-enterprise/internal/middleware"
+	"github.com/riyanathariq/dana-enterprise/internal/ratelimit"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
+	"github.com/riyanathariq/dana-enterprise/internal/route"
+	danaSDK "github.com/riyanathariq/dana-enterprise/internal/sdk/dana"
+	"github.com/riyanathariq/dana-enterprise/internal/sdk/dana/danatest"
+	"github.com/riyanathariq/dana-enterprise/internal/service/dana/merchant"
+	"github.com/riyanathariq/dana-enterprise/internal/service/dana/order"
+	"github.com/riyanathariq/dana-enterprise/internal/service/health"
+)
+
+// newTestServer serves the API, wired as in main, against a fake DANA gateway
+// API clients and rate limits are off, and DANA calls are neither retried nor cut off by the circuit breaker
+func newTestServer(t *testing.T) (*httptest.Server, *danatest.Gateway, repository.OrderRepository) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	gw := danatest.NewGateway()
+	t.Cleanup(gw.Close)
+	gw.Setenv(t.Setenv)
+	t.Setenv("DANA_RETRY_MAX_ATTEMPTS", "1")
+	t.Setenv("DANA_BREAKER_FAILURE_THRESHOLD", "0")
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+	cfg.RateLimit.Enabled = false
+	merchants, err := danaSDK.NewRegistry(cfg)
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	authenticator, err := auth.NewAuthenticator(cfg.Auth)
+	if err != nil {
+		t.Fatalf("NewAuthenticator: %v", err)
+	}
+
+	ledger := repository.NewMemoryOrderRepository()
+	orderService := order.NewService(cfg, merchants, ledger, ledger)
+	merchantService := merchant.NewService(merchants, cfg.Merchant)
+	danaHandler := handler.NewDanaHandler(cfg, merchants, merchantService, orderService)
+	healthHandler := handler.NewHealthHandler(health.NewService(merchants, merchantService, ledger, cfg.Readiness))
+	limiter := middleware.NewRateLimiter(ratelimit.NewMemoryStore(), cfg.RateLimit)
+
+	server := httptest.NewServer(route.SetupRoutes(danaHandler, healthHandler, authenticator, limiter, ledger, cfg.Idempotency.TTL))
+	t.Cleanup(server.Close)
+	return server, gw, ledger
+}
+
+// call sends a request with an optional JSON body and decodes the JSON response
+func call(t *testing.T, method, url string, body interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("encode request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return do(t, req)
+}
+
+// do sends a request and decodes the JSON response
+func do(t *testing.T, req *http.Request) (int, map[string]interface{}) {
+	t.Helper()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", req.Method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	var decoded map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("%s %s: decode response: %v", req.Method, req.URL.Path, err)
+	}
+	return resp.StatusCode, decoded
+}
+
+// hostedOrder is a valid hosted checkout order request of IDR 10.000
+func hostedOrder(ref string) map[string]interface{} {
+	return map[string]interface{}{
+		"partner_reference_no": ref,
+		"amount":               map[string]string{"value": "10000.00", "currency": "IDR"},
+		"url_params": []map[string]string{
+			{"url": "https://merchant.example/return", "type": "PAY_RETURN", "is_deeplink": "N"},
+			{"url": "https://merchant.example/notify", "type": "NOTIFICATION", "is_deeplink": "N"},
+		},
+	}
+}
+
+// createOrder creates a hosted checkout order through the API
+func createOrder(t *testing.T, serverURL, ref string) {
+	t.Helper()
+	status, body := call(t, http.MethodPost, serverURL+"/api/v1/order", hostedOrder(ref))
+	if status != http.StatusOK {
+		t.Fatalf("create order %s: status = %d, body = %v", ref, status, body)
+	}
+}
+
+func TestDanaHandlerOrderLifecycle(t *testing.T) {
+	server, gw, ledger := newTestServer(t)
+	createOrder(t, server.URL, "ORDER-API-1")
+
+	// DANA reports the payment through finish-notify
+	req, err := gw.NewFinishNotifyRequest(server.URL+"/api/v1/webhook/dana/finish-notify", "ORDER-API-1", danatest.StatusSuccess)
+	if err != nil {
+		t.Fatalf("NewFinishNotifyRequest: %v", err)
+	}
+	status, body := do(t, req)
+	if status != http.StatusOK || body["responseCode"] != "2005600" {
+		t.Fatalf("finish notify: status = %d, body = %v", status, body)
+	}
+	stored, err := ledger.Get(context.Background(), "ORDER-API-1")
+	if err != nil {
+		t.Fatalf("ledger Get: %v", err)
+	}
+	if stored.Status != repository.StatusSuccess {
+		t.Errorf("ledger status = %s, want %s", stored.Status, repository.StatusSuccess)
+	}
+
+	status, body = call(t, http.MethodGet, server.URL+"/api/v1/order/ORDER-API-1", nil)
+	if status != http.StatusOK {
+		t.Fatalf("get order: status = %d, body = %v", status, body)
+	}
+
+	status, body = call(t, http.MethodPost, server.URL+"/api/v1/order/ORDER-API-1/refunds", map[string]interface{}{
+		"partner_refund_no": "REFUND-API-1",
+		"amount":            map[string]string{"value": "10000.00", "currency": "IDR"},
+	})
+	if status != http.StatusOK {
+		t.Fatalf("refund: status = %d, body = %v", status, body)
+	}
+
+	status, body = call(t, http.MethodGet, server.URL+"/api/v1/refunds/REFUND-API-1", nil)
+	data, _ := body["data"].(map[string]interface{})
+	if status != http.StatusOK || data["status"] != repository.RefundStatusSuccess {
+		t.Fatalf("get refund: status = %d, body = %v", status, body)
+	}
+}
+
+func TestDanaHandlerMapsScriptedErrors(t *testing.T) {
+	customOrder := hostedOrder("ORDER-ERR-CUSTOM")
+	customOrder["pay_option_details"] = []map[string]interface{}{{
+		"pay_method":   "VIRTUAL_ACCOUNT",
+		"pay_option":   "VIRTUAL_ACCOUNT_BCA",
+		"trans_amount": map[string]string{"value": "10000.00", "currency": "IDR"},
+	}}
+
+	tests := []struct {
+		name       string
+		op         danatest.Operation
+		script     danatest.ScriptedResponse
+		setup      bool // Create ORDER-ERR-1 before scripting the error
+		method     string
+		path       string
+		body       interface{}
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "merchant info",
+			op:         danatest.OpQueryMerchantResource,
+			script:     danatest.ScriptedResponse{StatusCode: 400, ResponseCode: "PARAM_ILLEGAL", ResponseMessage: "Invalid merchant"},
+			method:     http.MethodGet,
+			path:       "/api/v1/merchant/info",
+			wantStatus: http.StatusBadGateway,
+			wantCode:   "DANA_ERROR",
+		},
+		{
+			name:       "create hosted order",
+			op:         danatest.OpCreateOrder,
+			script:     danatest.ScriptedResponse{StatusCode: 409, ResponseCode: "4095401"},
+			method:     http.MethodPost,
+			path:       "/api/v1/order",
+			body:       hostedOrder("ORDER-ERR-HOSTED"),
+			wantStatus: http.StatusConflict,
+			wantCode:   "DUPLICATE_PARTNER_REFERENCE_NO",
+		},
+		{
+			name:       "create custom order",
+			op:         danatest.OpCreateOrder,
+			script:     danatest.ScriptedResponse{StatusCode: 403, ResponseCode: "4035414"},
+			method:     http.MethodPost,
+			path:       "/api/v1/order/custom",
+			body:       customOrder,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "INSUFFICIENT_FUNDS",
+		},
+		{
+			name:       "payment method",
+			op:         danatest.OpConsultPay,
+			script:     danatest.ScriptedResponse{StatusCode: 400, ResponseCode: "4000002"},
+			method:     http.MethodGet,
+			path:       "/api/v1/order/payment/method?amount=10000.00",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "MISSING_MANDATORY_FIELD",
+		},
+		{
+			name:       "get order",
+			op:         danatest.OpQueryPayment,
+			script:     danatest.ScriptedResponse{StatusCode: 404, ResponseCode: "4045501"},
+			method:     http.MethodGet,
+			path:       "/api/v1/order/ORDER-ERR-1",
+			setup:      true,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "TRANSACTION_NOT_FOUND",
+		},
+		{
+			name:       "cancel order",
+			op:         danatest.OpCancelOrder,
+			script:     danatest.ScriptedResponse{StatusCode: 403, ResponseCode: "4035715"},
+			method:     http.MethodPost,
+			path:       "/api/v1/order/ORDER-ERR-1/cancel",
+			body:       map[string]string{"reason": "Customer request"},
+			setup:      true,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "TRANSACTION_NOT_PERMITTED",
+		},
+		{
+			name:   "refund order",
+			op:     danatest.OpRefundOrder,
+			script: danatest.ScriptedResponse{StatusCode: 404, ResponseCode: "4045813"},
+			method: http.MethodPost,
+			path:   "/api/v1/order/ORDER-ERR-1/refunds",
+			body: map[string]interface{}{
+				"partner_refund_no": "REFUND-ERR-1",
+				"amount":            map[string]string{"value": "5000.00", "currency": "IDR"},
+			},
+			setup:      true,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "INVALID_AMOUNT",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, gw, _ := newTestServer(t)
+			if tt.setup {
+				createOrder(t, server.URL, "ORDER-ERR-1")
+			}
+			gw.Enqueue(tt.op, tt.script)
+
+			status, body := call(t, tt.method, server.URL+tt.path, tt.body)
+			if status != tt.wantStatus || body["code"] != tt.wantCode {
+				t.Fatalf("status = %d, code = %v, want %d %s (body %v)", status, body["code"], tt.wantStatus, tt.wantCode, body)
+			}
+			if n := len(gw.Requests(tt.op)); n != 1 {
+				t.Errorf("%s requests = %d, want 1", tt.op, n)
+			}
+		})
+	}
+}
+
+// setMerchant registers a merchant besides the default one through DANA_MERCHANTS_PATH,
+// with the merchant and DANA keys of gw
+func setMerchant(t *testing.T, merchantID string, gw *danatest.Gateway) {
+	t.Helper()
+	merchants, err := json.Marshal(map[string]interface{}{
+		"merchants": []map[string]string{{
+			"merchant_id":   merchantID,
+			"client_id":     "CLIENT-" + merchantID,
+			"client_secret": "SECRET-" + merchantID,
+			"private_key":   gw.MerchantPrivateKeyPEM(),
+			"public_key":    gw.DanaPublicKeyPEM(),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("encode merchants: %v", err)
+	}
+	merchantsPath := filepath.Join(t.TempDir(), "merchants.yaml")
+	if err := os.WriteFile(merchantsPath, merchants, 0o600); err != nil {
+		t.Fatalf("write merchants: %v", err)
+	}
+	t.Setenv("DANA_MERCHANTS_PATH", merchantsPath)
+}
+
+// lockedBuffer is a bytes.Buffer safe for the log handler and the test to share
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestDanaHandlerRejectsUnknownMerchant(t *testing.T) {
+	gwB := danatest.NewGateway()
+	t.Cleanup(gwB.Close)
+	setMerchant(t, "MERCHANT-B", gwB)
+	server, _, _ := newTestServer(t)
+
+	var logs lockedBuffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	status, body := call(t, http.MethodGet, server.URL+"/api/v1/merchant/info/MERCHANT-X", nil)
+	if status != http.StatusBadRequest || body["code"] != "UNKNOWN_MERCHANT" {
+		t.Fatalf("status = %d, body = %v, want 400 UNKNOWN_MERCHANT", status, body)
+	}
+	// The error is attached to the request, so the access log says why it was rejected
+	if !strings.Contains(logs.String(), danaSDK.ErrUnknownMerchant.Error()) {
+		t.Errorf("access log does not report the unknown merchant:\n%s", logs.String())
+	}
+}
+
+// signedNotify builds a finish-notify request for path with body, signed by signer's DANA key
+func signedNotify(t *testing.T, serverURL, path string, body []byte, signer *danatest.Gateway) *http.Request {
+	t.Helper()
+	timestamp, signature, err := signer.SignWebhook(http.MethodPost, path, body)
+	if err != nil {
+		t.Fatalf("SignWebhook: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, serverURL+path, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-TIMESTAMP", timestamp)
+	req.Header.Set("X-SIGNATURE", signature)
+	return req
+}
+
+func TestFinishNotifyBindsKeyToMerchant(t *testing.T) {
+	// MERCHANT-B has its own DANA key pair, held by a second gateway
+	gwB := danatest.NewGateway()
+	t.Cleanup(gwB.Close)
+	setMerchant(t, "MERCHANT-B", gwB)
+
+	server, gw, ledger := newTestServer(t)
+	createOrder(t, server.URL, "ORDER-WEBHOOK-1")
+	legit, err := gw.NewFinishNotifyRequest(server.URL+"/api/v1/webhook/dana/finish-notify", "ORDER-WEBHOOK-1", danatest.StatusSuccess)
+	if err != nil {
+		t.Fatalf("NewFinishNotifyRequest: %v", err)
+	}
+	bodyA, err := io.ReadAll(legit.Body)
+	if err != nil {
+		t.Fatalf("read notify body: %v", err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(bodyA, &payload); err != nil {
+		t.Fatalf("decode notify body: %v", err)
+	}
+	payload["merchantId"] = "MERCHANT-B"
+	bodyB, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("encode notify body: %v", err)
+	}
+
+	rejected := []struct {
+		name       string
+		path       string
+		body       []byte
+		signer     *danatest.Gateway
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "merchant A signed with merchant B key on B's URL",
+			path:       "/api/v1/webhook/dana/finish-notify/MERCHANT-B",
+			body:       bodyA,
+			signer:     gwB,
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "4015600",
+		},
+		{
+			name:       "merchant A signed with merchant B key on A's URL",
+			path:       "/api/v1/webhook/dana/finish-notify/" + danatest.TestMerchantID,
+			body:       bodyA,
+			signer:     gwB,
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "4015600",
+		},
+		{
+			name:       "merchant A signed with merchant B key on the legacy URL",
+			path:       "/api/v1/webhook/dana/finish-notify",
+			body:       bodyA,
+			signer:     gwB,
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "4015600",
+		},
+		{
+			name:       "merchant B signed with merchant A key on A's URL",
+			path:       "/api/v1/webhook/dana/finish-notify/" + danatest.TestMerchantID,
+			body:       bodyB,
+			signer:     gw,
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "4015600",
+		},
+		{
+			name:       "merchant B signed with merchant A key on the legacy URL",
+			path:       "/api/v1/webhook/dana/finish-notify",
+			body:       bodyB,
+			signer:     gw,
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "4015600",
+		},
+		{
+			name:       "unknown merchant URL",
+			path:       "/api/v1/webhook/dana/finish-notify/MERCHANT-X",
+			body:       bodyA,
+			signer:     gw,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "4045608",
+		},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := do(t, signedNotify(t, server.URL, tt.path, tt.body, tt.signer))
+			if status != tt.wantStatus || body["responseCode"] != tt.wantCode {
+				t.Fatalf("status = %d, body = %v, want %d %s", status, body, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+	stored, err := ledger.Get(context.Background(), "ORDER-WEBHOOK-1")
+	if err != nil {
+		t.Fatalf("ledger Get: %v", err)
+	}
+	if stored.Status == repository.StatusSuccess {
+		t.Fatalf("rejected notifications marked the order %s", stored.Status)
+	}
+
+	// Merchant A's own notification on its URL is accepted
+	path := "/api/v1/webhook/dana/finish-notify/" + danatest.TestMerchantID
+	status, body := do(t, signedNotify(t, server.URL, path, bodyA, gw))
+	if status != http.StatusOK || body["responseCode"] != "2005600" {
+		t.Fatalf("finish notify: status = %d, body = %v", status, body)
+	}
+	stored, err = ledger.Get(context.Background(), "ORDER-WEBHOOK-1")
+	if err != nil {
+		t.Fatalf("ledger Get: %v", err)
+	}
+	if stored.Status != repository.StatusSuccess {
+		t.Errorf("ledger status = %s, want %s", stored.Status, repository.StatusSuccess)
+	}
+}
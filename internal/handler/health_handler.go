@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riyanathariq/dana-enterprise/internal/service/health"
+)
+
+// HealthHandler serves the liveness and readiness probes
+type HealthHandler struct {
+	health   *health.Service
+	draining atomic.Bool
+}
+
+func NewHealthHandler(healthService *health.Service) *HealthHandler {
+	return &HealthHandler{health: healthService}
+}
+
+// Drain makes readiness fail from now on, so load balancers stop routing new requests during shutdown
+func (h *HealthHandler) Drain() {
+	h.draining.Store(true)
+}
+
+// Live godoc
+// @Summary Liveness probe
+// @Description Report that the process is running; it never checks dependencies, so a failing DANA does not restart the API
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /live [get]
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "ok",
+		"message": "Dana Enterprise API is running",
+	})
+}
+
+// Ready godoc
+// @Summary Readiness probe
+// @Description Run the readiness checks: order ledger, merchant private and public keys, DANA circuit breaker and,
+// @Description when enabled, a cached DANA merchant resource query. Each check reports its status and latency.
+// @Description Degraded checks keep the API ready; a failed check or a shutdown in progress returns 503
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /ready [get]
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":  "not_ready",
+			"message": "Dana Enterprise API is shutting down",
+		})
+		return
+	}
+
+	report := h.health.Check(c.Request.Context())
+	switch report.Status {
+	case health.StatusFail:
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":  "not_ready",
+			"message": "Dana Enterprise API is not ready, see the failed checks",
+			"checks":  report.Checks,
+		})
+	case health.StatusDegraded:
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "degraded",
+			"message": "Dana Enterprise API is ready, see the degraded checks",
+			"checks":  report.Checks,
+		})
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "ok",
+			"message": "Dana Enterprise API is ready",
+			"checks":  report.Checks,
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riyanathariq/dana-enterprise/internal/mapper"
+	"github.com/riyanathariq/dana-enterprise/internal/model"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
+	"github.com/riyanathariq/dana-enterprise/internal/service/dana/order"
+)
+
+// parseListTime parses RFC3339 timestamps or YYYY-MM-DD dates in Jakarta timezone
+// A date-only upper bound covers the whole day
+func parseListTime(value string, upperBound bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	jakartaTz, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		jakartaTz = time.FixedZone("WIB", 7*60*60)
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, jakartaTz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time %q: use RFC3339 or YYYY-MM-DD", value)
+	}
+	if upperBound {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// ListOrders godoc
+// @Summary List stored orders
+// @Description List orders recorded in the local ledger with filters and cursor pagination, without calling Dana API
+// @Tags order
+// @Accept json
+// @Produce json
+// @Param status query string false "Order status (INITIATED, PAYING, PENDING, SUCCESS, REFUNDED, CANCELLED, FAILED, EXPIRED)"
+// @Param merchant_id query string false "Merchant ID"
+// @Param sub_merchant_id query string false "Sub Merchant ID"
+// @Param external_store_id query string false "External Store ID"
+// @Param client_id query string false "API client that created the order"
+// @Param created_from query string false "Created at or after (RFC3339 or YYYY-MM-DD)"
+// @Param created_to query string false "Created before (RFC3339), or on/before date (YYYY-MM-DD)"
+// @Param min_amount query string false "Minimum amount (e.g. 10000.00)"
+// @Param max_amount query string false "Maximum amount (e.g. 50000.00)"
+// @Param cursor query string false "Cursor from previous page meta.next_cursor"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} model.OrderListResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/orders [get]
+func (h *DanaHandler) ListOrders(c *gin.Context) {
+	params := order.ListOrdersParams{
+		Status:          strings.ToUpper(c.Query("status")),
+		MerchantID:      c.Query("merchant_id"),
+		SubMerchantID:   c.Query("sub_merchant_id"),
+		ExternalStoreID: c.Query("external_store_id"),
+		ClientID:        c.Query("client_id"),
+		MinAmount:       c.Query("min_amount"),
+		MaxAmount:       c.Query("max_amount"),
+		Cursor:          c.Query("cursor"),
+		Limit:           repository.DefaultListLimit,
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Success: false,
+				Error:   "limit must be a positive integer",
+				Code:    "VALIDATION_ERROR",
+				Details: fmt.Sprintf("limit must be between 1 and %d", repository.MaxListLimit),
+			})
+			return
+		}
+		if parsed > repository.MaxListLimit {
+			parsed = repository.MaxListLimit
+		}
+		params.Limit = parsed
+	}
+
+	var err error
+	if params.CreatedFrom, err = parseListTime(c.Query("created_from"), false); err == nil {
+		params.CreatedTo, err = parseListTime(c.Query("created_to"), true)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "VALIDATION_ERROR",
+			Details: "Invalid date range",
+		})
+		return
+	}
+
+	orders, nextCursor, err := h.orderService.ListOrders(c.Request.Context(), params)
+	if err != nil {
+		if respondServiceError(c, err) {
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "LIST_ORDERS_ERROR",
+			Details: "Failed to list orders",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, mapper.MapOrderList(orders, params.Limit, nextCursor))
+}
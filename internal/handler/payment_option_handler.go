@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riyanathariq/dana-enterprise/internal/model"
+)
+
+// GetPaymentOptions godoc
+// @Summary List supported pay options
+// @Description List the pay method and pay option pairs accepted in pay_option_details, with the tokens each one needs
+// @Tags payment
+// @Produce json
+// @Success 200 {object} model.PayOptionListResponse
+// @Router /api/v1/payment/options [get]
+func (h *DanaHandler) GetPaymentOptions(c *gin.Context) {
+	c.JSON(http.StatusOK, model.PayOptionListResponse{
+		Success: true,
+		Message: "Payment options retrieved successfully",
+		Data:    model.PayOptions(),
+	})
+}
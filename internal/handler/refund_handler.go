@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/dana-id/dana-go/payment_gateway/v1"
+	"github.com/gin-gonic/gin"
+	"github.com/riyanathariq/dana-enterprise/internal/mapper"
+	"github.com/riyanathariq/dana-enterprise/internal/model"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
+	"github.com/riyanathariq/dana-enterprise/internal/service/dana/order"
+)
+
+// RefundOrder godoc
+// @Summary Refund an order
+// @Description Issue a full or partial refund for a paid order in DANA Payment Gateway
+// @Tags refund
+// @Accept json
+// @Produce json
+// @Param partner_reference_no path string true "Partner Reference Number"
+// @Param request body model.RefundOrderRequest true "Refund Order Request"
+// @Success 200 {object} map[string]interface{}
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 422 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/order/{partner_reference_no}/refunds [post]
+func (h *DanaHandler) RefundOrder(c *gin.Context) {
+	partnerReferenceNo := c.Param("partner_reference_no")
+	if partnerReferenceNo == "" {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Error:   "partner_reference_no is required",
+			Code:    "VALIDATION_ERROR",
+			Details: "Partner reference number must be provided as path parameter",
+		})
+		return
+	}
+
+	var req model.RefundOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "VALIDATION_ERROR",
+			Details: "Invalid request body",
+		})
+		return
+	}
+
+	// Convert request model to service params
+	params := order.RefundOrderRequestParams{
+		PartnerReferenceNo: partnerReferenceNo,
+		PartnerRefundNo:    req.PartnerRefundNo,
+		MerchantID:         req.MerchantID,
+		Amount: payment_gateway.Money{
+			Value:    req.Amount.Value,
+			Currency: req.Amount.Currency,
+		},
+		Reason: req.Reason,
+	}
+
+	result, err := h.orderService.RefundOrder(c.Request.Context(), params)
+	if err != nil {
+		if respondDanaError(c, err) || respondServiceError(c, err) {
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "REFUND_ORDER_ERROR",
+			Details: "Failed to refund order in Dana API",
+		})
+		return
+	}
+
+	// DANA did not answer clearly, the reconciler resolves the refund later
+	if result.Status == repository.RefundStatusPending {
+		c.JSON(http.StatusAccepted, gin.H{
+			"success": true,
+			"message": "Refund pending, check its status with GET /api/v1/refunds/{partner_refund_no}",
+			"data":    mapper.MapRefund(result),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Order refunded successfully",
+		"data":    mapper.MapRefund(result),
+	})
+}
+
+// GetRefund godoc
+// @Summary Get refund status
+// @Description Get refund details by partner refund number
+// @Tags refund
+// @Accept json
+// @Produce json
+// @Param partner_refund_no path string true "Partner Refund Number"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /api/v1/refunds/{partner_refund_no} [get]
+func (h *DanaHandler) GetRefund(c *gin.Context) {
+	partnerRefundNo := c.Param("partner_refund_no")
+	if partnerRefundNo == "" {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Success: false,
+			Error:   "partner_refund_no is required",
+			Code:    "VALIDATION_ERROR",
+			Details: "Partner refund number must be provided as path parameter",
+		})
+		return
+	}
+
+	result, err := h.orderService.GetRefund(c.Request.Context(), partnerRefundNo)
+	if err != nil {
+		if respondServiceError(c, err) {
+			return
+		}
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    "GET_REFUND_ERROR",
+			Details: "Failed to get refund",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Refund retrieved successfully",
+		"data":    mapper.MapRefund(result),
+	})
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riyanathariq/dana-enterprise/internal/model"
+	danaSDK "github.com/riyanathariq/dana-enterprise/internal/sdk/dana"
+)
+
+// webhookPublicKey returns the DANA public key for a finish-notify URL: the key of the merchant in the path,
+// or the default merchant's key on the legacy URL. The unverified body never selects the key
+func (h *DanaHandler) webhookPublicKey(routeMerchantID string) (string, error) {
+	if routeMerchantID == "" {
+		if merchant := h.merchants.Default(); merchant != nil {
+			return merchant.Config.PublicKey, nil
+		}
+		return h.cfg.Dana.PublicKey, nil
+	}
+	merchant, err := h.merchants.Get(routeMerchantID)
+	if err != nil {
+		return "", err
+	}
+	return merchant.Config.PublicKey, nil
+}
+
+// notifyMerchantMatches reports whether a verified notification belongs to the URL it was sent to
+// On a per-merchant URL its merchantId must be the one in the path; on the legacy URL its merchant must share
+// the key it was verified with, so a merchant with its own key cannot sign for another merchant
+func (h *DanaHandler) notifyMerchantMatches(routeMerchantID, publicKey, notifyMerchantID string) bool {
+	if routeMerchantID != "" {
+		return notifyMerchantID == routeMerchantID
+	}
+	merchant, err := h.merchants.Get(notifyMerchantID)
+	return err == nil && merchant.Config.PublicKey == publicKey
+}
+
+// FinishNotify godoc
+// @Summary Receive DANA payment notification
+// @Description Receive finish-notify webhook from DANA, verify X-SIGNATURE with the public key of the merchant in the path (DANA_PUBLIC_KEY on the legacy URL) and X-TIMESTAMP against DANA_WEBHOOK_MAX_SKEW,, reject a merchantId that does not belong to the URL, and acknowledge it
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param X-TIMESTAMP header string true "Request timestamp, within DANA_WEBHOOK_MAX_SKEW of the server clock"
+// @Param X-SIGNATURE header string true "Request signature"
+// @Param merchant_id path string false "Merchant ID, omitted on the legacy URL of the default merchant"
+// @Success 200 {object} model.WebhookAckResponse
+// @Failure 400 {object} model.WebhookAckResponse
+// @Failure 401 {object} model.WebhookAckResponse
+// @Failure 404 {object} model.WebhookAckResponse
+// @Failure 500 {object} model.WebhookAckResponse
+// @Router /api/v1/webhook/dana/finish-notify [post]
+// @Router /api/v1/webhook/dana/finish-notify/{merchant_id} [post]
+func (h *DanaHandler) FinishNotify(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.WebhookAckResponse{
+			ResponseCode:    "4005601",
+			ResponseMessage: "Invalid Field Format",
+		})
+		return
+	}
+
+	routeMerchantID := c.Param("merchant_id")
+	publicKey, err := h.webhookPublicKey(routeMerchantID)
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusNotFound, model.WebhookAckResponse{
+			ResponseCode:    "4045608",
+			ResponseMessage: "Invalid Merchant",
+		})
+		return
+	}
+
+	notify, err := danaSDK.ParseFinishNotify(
+		publicKey,
+		c.Request.Method,
+		c.Request.URL.Path,
+		body,
+		c.GetHeader("X-TIMESTAMP"),
+		c.GetHeader("X-SIGNATURE"),
+		h.cfg.Dana.WebhookMaxSkew,
+		time.Now(),
+	)
+	if err != nil {
+		c.Error(err)
+		if errors.Is(err, danaSDK.ErrInvalidSignature) {
+			c.JSON(http.StatusUnauthorized, model.WebhookAckResponse{
+				ResponseCode:    "4015600",
+				ResponseMessage: "Unauthorized. Invalid Signature",
+			})
+			return
+		}
+		if errors.Is(err, danaSDK.ErrInvalidPayload) {
+			c.JSON(http.StatusBadRequest, model.WebhookAckResponse{
+				ResponseCode:    "4005601",
+				ResponseMessage: "Invalid Field Format",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, model.WebhookAckResponse{
+			ResponseCode:    "5005601",
+			ResponseMessage: "Internal Server Error",
+		})
+		return
+	}
+
+	if !h.notifyMerchantMatches(routeMerchantID, publicKey, notify.MerchantId) {
+		c.Error(fmt.Errorf("%w: merchant %q does not match the notification URL", danaSDK.ErrInvalidSignature, notify.MerchantId))
+		c.JSON(http.StatusUnauthorized, model.WebhookAckResponse{
+			ResponseCode:    "4015600",
+			ResponseMessage: "Unauthorized. Merchant does not match the notification URL",
+		})
+		return
+	}
+
+	if err := h.orderService.HandleFinishNotify(c.Request.Context(), notify); err != nil {
+		c.Error(err)
+		c.JSON(http.StatusBadRequest, model.WebhookAckResponse{
+			ResponseCode:    "4005602",
+			ResponseMessage: "Invalid Mandatory Field",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.WebhookAckResponse{
+		ResponseCode:    "2005600",
+		ResponseMessage: "Successful",
+	})
+}
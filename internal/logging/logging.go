@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Options configures the application logger
+type Options struct {
+	Level  string // debug, info, warn or error
+	Format string // json or text
+}
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+)
+
+// WithRequestID returns a context carrying the inbound request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the inbound request ID carried by ctx, if any
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// ParseLevel parses a log level name, defaulting to info
+func ParseLevel(level string) (slog.Level, bool) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, true
+	case "", "info":
+		return slog.LevelInfo, true
+	case "warn", "warning":
+		return slog.LevelWarn, true
+	case "error":
+		return slog.LevelError, true
+	}
+	return slog.LevelInfo, false
+}
+
+// New creates a logger writing JSON (or text) records to w
+// Records logged with a context carry its request ID, and sensitive attributes are redacted
+func New(w io.Writer, opts Options) *slog.Logger {
+	level, _ := ParseLevel(opts.Level)
+	handlerOptions := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr,
+	}
+
+	var handler slog.Handler
+	if strings.ToLower(opts.Format) == "text" {
+		handler = slog.NewTextHandler(w, handlerOptions)
+	} else {
+		handler = slog.NewJSONHandler(w, handlerOptions)
+	}
+	return slog.New(contextHandler{handler})
+}
+
+// contextHandler adds the request ID of the record context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		r.AddAttrs(slog.String("request_id", requestID))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// redactAttr masks attributes whose key names a secret or personal identifier
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() != slog.KindGroup && IsSensitive(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	return a
+}
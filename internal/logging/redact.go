@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Redacted replaces the value of sensitive fields in logs
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are field and header names, lowercased without "-" and "_", whose values are never logged
+var sensitiveKeys = map[string]bool{
+	// Signatures and credentials
+	"signature":     true,
+	"xsignature":    true,
+	"authorization": true,
+	"accesstoken":   true,
+	"refreshtoken":  true,
+	"token":         true,
+	"tokenid":       true,
+	"clientsecret":  true,
+	"apikey":        true,
+	"xapikey":       true,
+	"hmacsecret":    true,
+	"privatekey":    true,
+	"password":      true,
+	"otp":           true,
+	// Payment instruments
+	"cardtoken":     true,
+	"merchanttoken": true,
+	"cardno":        true,
+	"cvv":           true,
+	// Buyer identifiers
+	"userid":         true,
+	"externaluserid": true,
+	"nickname":       true,
+	"email":          true,
+	"phone":          true,
+	"phoneno":        true,
+	"mobileno":       true,
+	"mobilenumber":   true,
+	"clientip":       true,
+	"sessionid":      true,
+}
+
+// normalizeKey lowercases a key and strips separators so "X-SIGNATURE", "card_token" and "cardToken" match
+func normalizeKey(key string) string {
+	key = strings.ToLower(key)
+	key = strings.ReplaceAll(key, "-", "")
+	return strings.ReplaceAll(key, "_", "")
+}
+
+// IsSensitive reports whether values of a field or header must be redacted
+func IsSensitive(key string) bool {
+	return sensitiveKeys[normalizeKey(key)]
+}
+
+// RedactHeaders returns headers as a flat map with sensitive values redacted
+func RedactHeaders(header http.Header) map[string]string {
+	redacted := make(map[string]string, len(header))
+	for key, values := range header {
+		if IsSensitive(key) {
+			redacted[key] = Redacted
+			continue
+		}
+		redacted[key] = strings.Join(values, ", ")
+	}
+	return redacted
+}
+
+// RedactJSON returns a JSON body with sensitive fields redacted at any depth
+// Bodies that are not JSON are replaced entirely, as their content cannot be inspected
+func RedactJSON(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		redacted, _ := json.Marshal(Redacted + " non-JSON body")
+		return redacted
+	}
+
+	redacted, err := json.Marshal(redactValue(payload))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+// redactValue walks a decoded JSON value replacing sensitive fields
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if IsSensitive(key) {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+		return v
+	}
+	return value
+}
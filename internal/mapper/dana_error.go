@@ -0,0 +1,92 @@
+package mapper
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/riyanathariq/dana-enterprise/internal/model"
+	danaSDK "github.com/riyanathariq/dana-enterprise/internal/sdk/dana"
+)
+
+// danaErrorEntry is how a DANA error is reported to our clients
+type danaErrorEntry struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+// danaErrorCatalogue maps SNAP HTTP status and case code to our response
+// Case codes are shared by every SNAP service, so the service code is ignored
+var danaErrorCatalogue = map[string]danaErrorEntry{
+	"400-00": {http.StatusBadRequest, "DANA_BAD_REQUEST", "Request rejected by DANA"},
+	"400-01": {http.StatusBadRequest, "INVALID_FIELD_FORMAT", "Invalid field format"},
+	"400-02": {http.StatusBadRequest, "MISSING_MANDATORY_FIELD", "Missing mandatory field"},
+	"401-00": {http.StatusUnauthorized, "DANA_UNAUTHORIZED", "DANA rejected the merchant credentials or signature"},
+	"401-01": {http.StatusUnauthorized, "DANA_UNAUTHORIZED", "DANA rejected the access token"},
+	"403-00": {http.StatusUnprocessableEntity, "TRANSACTION_EXPIRED", "Transaction expired"},
+	"403-01": {http.StatusUnprocessableEntity, "FEATURE_NOT_ALLOWED", "Feature not allowed for this merchant"},
+	"403-02": {http.StatusUnprocessableEntity, "EXCEEDS_AMOUNT_LIMIT", "Exceeds transaction amount limit"},
+	"403-03": {http.StatusUnprocessableEntity, "SUSPECTED_FRAUD", "Transaction suspected as fraud"},
+	"403-05": {http.StatusUnprocessableEntity, "DO_NOT_HONOR", "Transaction declined"},
+	"403-14": {http.StatusUnprocessableEntity, "INSUFFICIENT_FUNDS", "Insufficient funds"},
+	"403-15": {http.StatusUnprocessableEntity, "TRANSACTION_NOT_PERMITTED", "Transaction not permitted"},
+	"403-18": {http.StatusUnprocessableEntity, "INACTIVE_ACCOUNT", "Inactive account"},
+	"404-00": {http.StatusConflict, "INVALID_TRANSACTION_STATUS", "Operation not allowed in the current transaction status"},
+	"404-01": {http.StatusNotFound, "TRANSACTION_NOT_FOUND", "Transaction not found"},
+	"404-04": {http.StatusConflict, "TRANSACTION_CANCELLED", "Transaction already cancelled"},
+	"404-08": {http.StatusNotFound, "INVALID_MERCHANT", "Merchant not found at DANA"},
+	"404-13": {http.StatusUnprocessableEntity, "INVALID_AMOUNT", "Invalid amount"},
+	"404-18": {http.StatusConflict, "INCONSISTENT_REQUEST", "Request is inconsistent with a previous request using the same identifiers"},
+	"405-00": {http.StatusUnprocessableEntity, "OPERATION_NOT_SUPPORTED", "Requested function is not supported"},
+	"405-01": {http.StatusUnprocessableEntity, "OPERATION_NOT_ALLOWED", "Requested operation is not allowed"},
+	"409-00": {http.StatusConflict, "DANA_CONFLICT", "Conflicting request"},
+	"409-01": {http.StatusConflict, "DUPLICATE_PARTNER_REFERENCE_NO", "Duplicate partnerReferenceNo"},
+	"429-00": {http.StatusBadGateway, "DANA_RATE_LIMITED", "DANA is rate limiting requests"},
+	"500-00": {http.StatusBadGateway, "DANA_GENERAL_ERROR", "DANA general error"},
+	"500-01": {http.StatusBadGateway, "DANA_INTERNAL_ERROR", "DANA internal server error"},
+	"500-02": {http.StatusBadGateway, "DANA_EXTERNAL_ERROR", "DANA external server error"},
+	"504-00": {http.StatusBadGateway, "DANA_TIMEOUT", "DANA timed out"},
+}
+
+// danaErrorByStatus is used for codes missing from the catalogue, keyed by DANA HTTP status
+var danaErrorByStatus = map[int]danaErrorEntry{
+	http.StatusBadRequest:       {http.StatusBadRequest, "DANA_BAD_REQUEST", "Request rejected by DANA"},
+	http.StatusUnauthorized:     {http.StatusUnauthorized, "DANA_UNAUTHORIZED", "DANA rejected the merchant credentials or signature"},
+	http.StatusForbidden:        {http.StatusUnprocessableEntity, "TRANSACTION_NOT_PERMITTED", "Transaction not permitted"},
+	http.StatusNotFound:         {http.StatusNotFound, "DANA_NOT_FOUND", "Not found at DANA"},
+	http.StatusMethodNotAllowed: {http.StatusUnprocessableEntity, "OPERATION_NOT_ALLOWED", "Requested operation is not allowed"},
+	http.StatusConflict:         {http.StatusConflict, "DANA_CONFLICT", "Conflicting request"},
+}
+
+// MapDanaError maps a DANA error to our HTTP status and error response
+// Code is stable for clients to branch on; the DANA responseCode is kept in Details
+func MapDanaError(danaErr *danaSDK.DanaError) (int, *model.ErrorResponse) {
+	// SNAP codes carry the HTTP status DANA meant, even when the transport reported another one
+	key := fmt.Sprintf("%d-", danaErr.HTTPStatus)
+	if caseCode := danaErr.CaseCode(); caseCode != "" {
+		key = danaErr.ResponseCode[:3] + "-" + caseCode
+	}
+
+	entry, ok := danaErrorCatalogue[key]
+	if !ok {
+		entry, ok = danaErrorByStatus[danaErr.HTTPStatus]
+	}
+	if !ok {
+		entry = danaErrorEntry{http.StatusBadGateway, "DANA_ERROR", "Unexpected error from DANA"}
+		if danaErr.HTTPStatus >= 400 && danaErr.HTTPStatus < 500 {
+			entry = danaErrorEntry{http.StatusUnprocessableEntity, "DANA_REJECTED", "Request rejected by DANA"}
+		}
+	}
+
+	details := fmt.Sprintf("DANA HTTP %d: %s", danaErr.HTTPStatus, danaErr.ResponseMessage)
+	if danaErr.ResponseCode != "" {
+		details = fmt.Sprintf("DANA responseCode %s: %s", danaErr.ResponseCode, danaErr.ResponseMessage)
+	}
+
+	return entry.Status, &model.ErrorResponse{
+		Success: false,
+		Error:   entry.Message,
+		Code:    entry.Code,
+		Details: details,
+	}
+}
@@ -2,11 +2,12 @@ package mapper
 
 import (
 	"encoding/json"
-	"fmt"
+	"strings"
 	"time"
 
 	"github.com/dana-id/dana-go/merchant_management/v1"
 	"github.com/riyanathariq/dana-enterprise/internal/model"
+	"github.com/riyanathariq/dana-enterprise/internal/money"
 )
 
 // MapMerchantResourceResponse maps Dana API response to our clean response model
@@ -49,22 +50,25 @@ func MapMerchantResourceResponse(merchantID string, danaResponse *merchant_manag
 
 			if resourceType != "" && resourceValue != "" {
 				// Parse value JSON to get amount and currency
+				// Numbers are decoded as json.Number, so amounts are never rounded through float64
 				var valueData map[string]interface{}
-				if err := json.Unmarshal([]byte(resourceValue), &valueData); err == nil {
+				decoder := json.NewDecoder(strings.NewReader(resourceValue))
+				decoder.UseNumber()
+				if err := decoder.Decode(&valueData); err == nil {
+					currency := money.IDR
+					if currencyVal, ok := valueData["currency"]; ok {
+						if currencyStr, ok := currencyVal.(string); ok {
+							currency = currencyStr
+						}
+					}
+
 					// Extract amount
 					amount := ""
 					if amountVal, ok := valueData["amount"]; ok {
 						if amountStr, ok := amountVal.(string); ok {
 							amount = amountStr
-						} else if amountFloat, ok := amountVal.(float64); ok {
-							amount = formatAmount(amountFloat)
-						}
-					}
-
-					currency := "IDR"
-					if currencyVal, ok := valueData["currency"]; ok {
-						if currencyStr, ok := currencyVal.(string); ok {
-							currency = currencyStr
+						} else if amountNumber, ok := amountVal.(json.Number); ok {
+							amount = formatAmount(amountNumber, currency)
 						}
 					}
 
@@ -128,8 +132,12 @@ func getResourceDescription(resourceType string) string {
 	return ""
 }
 
-// formatAmount formats float64 amount to string
-func formatAmount(amount float64) string {
-	// Format to string without unnecessary decimal places
-	return fmt.Sprintf("%.0f", amount)
+// formatAmount formats a numeric amount like DANA string amounts, e.g. 10000 as "10000.00"
+// Values money cannot represent exactly are returned as sent
+func formatAmount(amount json.Number, currency string) string {
+	m, err := money.Parse(amount.String(), currency)
+	if err != nil {
+		return amount.String()
+	}
+	return m.String()
 }
@@ -0,0 +1,100 @@
+package mapper
+
+import (
+	"time"
+
+	"github.com/riyanathariq/dana-enterprise/internal/model"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
+	"github.com/riyanathariq/dana-enterprise/internal/service/dana/order"
+)
+
+// MapOrder maps a ledger order to our clean response model
+func MapOrder(order *repository.Order) *model.OrderData {
+	if order == nil {
+		return nil
+	}
+
+	return &model.OrderData{
+		PartnerReferenceNo: order.PartnerReferenceNo,
+		MerchantID:         order.MerchantID,
+		SubMerchantID:      order.SubMerchantID,
+		ExternalStoreID:    order.ExternalStoreID,
+		Amount: model.MoneyRequest{
+			Value:    order.AmountValue,
+			Currency: order.AmountCurrency,
+		},
+		CheckoutType:   order.CheckoutType,
+		ReferenceNo:    order.ReferenceNo,
+		WebRedirectURL: order.WebRedirectURL,
+		Status:         order.Status,
+		ValidUpTo:      order.ValidUpTo,
+		ClientID:       order.ClientID,
+		CreatedAt:      order.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      order.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// MapOrderList maps a page of ledger orders to our clean list response
+func MapOrderList(orders []*repository.Order, limit int, nextCursor string) *model.OrderListResponse {
+	data := make([]*model.OrderData, len(orders))
+	for i, order := range orders {
+		data[i] = MapOrder(order)
+	}
+
+	return &model.OrderListResponse{
+		Success: true,
+		Message: "Orders retrieved successfully",
+		Data:    data,
+		Meta: &model.OrderListMeta{
+			Count:      len(data),
+			Limit:      limit,
+			NextCursor: nextCursor,
+		},
+	}
+}
+
+// MapCreateOrderResponse maps a created order to our clean response model
+// The payment code is exposed as QR payload or VA number according to the pay option it was created with
+func MapCreateOrderResponse(created *order.CreatedOrder, message string) *model.CreateOrderResponse {
+	data := &model.CreateOrderData{
+		PartnerReferenceNo: created.PartnerReferenceNo,
+		ReferenceNo:        created.ReferenceNo,
+		MerchantID:         created.MerchantID,
+		CheckoutType:       created.CheckoutType,
+		Amount:             mapMoney(created.Amount),
+		RedirectURL:        created.WebRedirectURL,
+		PaymentCode:        created.PaymentCode,
+		ExpiresAt:          created.ValidUpTo,
+	}
+	if len(created.PayOptionDetails) > 0 {
+		data.PayMethod = model.PayMethod(created.PayOptionDetails[0].PayMethod)
+		data.PayOption = model.PayOption(created.PayOptionDetails[0].PayOption)
+	}
+
+	switch paymentCodeCategory(created) {
+	case model.PayOptionCategoryQRIS:
+		data.QRPayload = created.PaymentCode
+	case model.PayOptionCategoryVirtualAccount:
+		data.VANumber = created.PaymentCode
+	}
+
+	return &model.CreateOrderResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+	}
+}
+
+// paymentCodeCategory returns the category of the first QRIS or VA pay option of an order with a payment code
+func paymentCodeCategory(created *order.CreatedOrder) model.PayOptionCategory {
+	if created.PaymentCode == "" {
+		return ""
+	}
+	for _, pod := range created.PayOptionDetails {
+		info, ok := model.LookupPayOption(model.PayMethod(pod.PayMethod), model.PayOption(pod.PayOption))
+		if ok && (info.Category == model.PayOptionCategoryQRIS || info.Category == model.PayOptionCategoryVirtualAccount) {
+			return info.Category
+		}
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+package mapper
+
+import (
+	"time"
+
+	"github.com/riyanathariq/dana-enterprise/internal/model"
+	"github.com/riyanathariq/dana-enterprise/internal/money"
+	"github.com/riyanathariq/dana-enterprise/internal/service/dana/order"
+)
+
+// MapPaymentMethods maps a payment method consultation to the API response
+func MapPaymentMethods(consultation *order.PaymentMethods) *model.PaymentMethodListResponse {
+	data := make([]*model.PaymentMethodData, 0, len(consultation.Methods))
+	for _, method := range consultation.Methods {
+		data = append(data, &model.PaymentMethodData{
+			PayMethod: method.PayMethod,
+			PayOption: method.PayOption,
+			Fee:       mapOptionalMoney(method.Fee),
+			MinAmount: mapOptionalMoney(method.MinAmount),
+			MaxAmount: mapOptionalMoney(method.MaxAmount),
+		})
+	}
+
+	return &model.PaymentMethodListResponse{
+		Success: true,
+		Message: "Payment method retrieved successfully",
+		Data:    data,
+		Meta: &model.PaymentMethodMeta{
+			MerchantID:      consultation.MerchantID,
+			ConsultedAmount: mapMoney(consultation.Amount),
+			Cached:          consultation.Cached,
+			FetchedAt:       consultation.FetchedAt.Format(time.RFC3339),
+		},
+	}
+}
+
+func mapMoney(m money.Money) model.MoneyRequest {
+	return model.MoneyRequest{Value: m.String(), Currency: m.Currency()}
+}
+
+func mapOptionalMoney(m *money.Money) *model.MoneyRequest {
+	if m == nil {
+		return nil
+	}
+	mapped := mapMoney(*m)
+	return &mapped
+}
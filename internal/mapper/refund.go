@@ -0,0 +1,30 @@
+package mapper
+
+import (
+	"time"
+
+	"github.com/riyanathariq/dana-enterprise/internal/model"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
+)
+
+// MapRefund maps a refund record to our clean response model
+func MapRefund(refund *repository.Refund) *model.RefundData {
+	if refund == nil {
+		return nil
+	}
+
+	return &model.RefundData{
+		PartnerRefundNo:    refund.PartnerRefundNo,
+		PartnerReferenceNo: refund.PartnerReferenceNo,
+		MerchantID:         refund.MerchantID,
+		RefundNo:           refund.RefundNo,
+		Amount: model.MoneyRequest{
+			Value:    refund.AmountValue,
+			Currency: refund.AmountCurrency,
+		},
+		Reason:     refund.Reason,
+		Status:     refund.Status,
+		RefundTime: refund.RefundTime,
+		CreatedAt:  refund.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,56 @@
+package mapper
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/riyanathariq/dana-enterprise/internal/errs"
+	"github.com/riyanathariq/dana-enterprise/internal/model"
+	"github.com/riyanathariq/dana-enterprise/internal/money"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
+)
+
+// serviceErrorCatalogue maps errors known to the services to our response, checked in order
+// Details describes the error to the client, Error is the service error itself
+var serviceErrorCatalogue = []struct {
+	err   error
+	entry serviceErrorEntry
+}{
+	{money.ErrInvalidAmount, invalidAmountEntry},
+	{money.ErrUnsupportedCurrency, invalidAmountEntry},
+	{money.ErrCurrencyMismatch, invalidAmountEntry},
+	{errs.ErrAmountMismatch, serviceErrorEntry{http.StatusUnprocessableEntity, "AMOUNT_MISMATCH", "The trans_amount values of pay_option_details must add up to amount"}},
+	{errs.ErrMalformedCreateOrderResponse, serviceErrorEntry{http.StatusBadGateway, "MALFORMED_DANA_RESPONSE", "The order was created in Dana API but its payment details could not be read, query it by partner_reference_no"}},
+	{errs.ErrInvalidPaymentMethodQuery, serviceErrorEntry{http.StatusBadRequest, "VALIDATION_ERROR", "Invalid payment method query"}},
+	{errs.ErrInvalidListFilter, serviceErrorEntry{http.StatusBadRequest, "VALIDATION_ERROR", "Invalid order filter"}},
+	{repository.ErrInvalidCursor, serviceErrorEntry{http.StatusBadRequest, "VALIDATION_ERROR", "Cursor must be taken from meta.next_cursor of a previous page"}},
+	{errs.ErrDuplicateRefund, serviceErrorEntry{http.StatusConflict, "DUPLICATE_REFUND", "Partner refund number has already been used"}},
+	{errs.ErrRefundExceedsAmount, serviceErrorEntry{http.StatusUnprocessableEntity, "REFUND_EXCEEDS_AMOUNT", "Cumulative refunded amount cannot exceed the original order amount"}},
+	{errs.ErrRefundNotFound, serviceErrorEntry{http.StatusNotFound, "REFUND_NOT_FOUND", "No refund found for the given partner refund number"}},
+}
+
+// serviceErrorEntry is how a service error is reported to our clients
+type serviceErrorEntry struct {
+	Status  int
+	Code    string
+	Details string
+}
+
+// invalidAmountEntry is the response for amounts that cannot be parsed as IDR
+var invalidAmountEntry = serviceErrorEntry{http.StatusBadRequest, "VALIDATION_ERROR", "Amounts must be non-negative IDR values with at most 2 decimal places, e.g. \"10000.00\""}
+
+// MapServiceError maps an error known to the services to our HTTP status and error response
+// It returns false for any other error
+func MapServiceError(err error) (int, *model.ErrorResponse, bool) {
+	for _, known := range serviceErrorCatalogue {
+		if errors.Is(err, known.err) {
+			return known.entry.Status, &model.ErrorResponse{
+				Success: false,
+				Error:   err.Error(),
+				Code:    known.entry.Code,
+				Details: known.entry.Details,
+			}, true
+		}
+	}
+	return 0, nil, false
+}
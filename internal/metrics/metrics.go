@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DANA operations used as the operation label
+const (
+	OperationCreateOrderHosted     = "create_order_hosted"
+	OperationCreateOrderCustom     = "create_order_custom"
+	OperationQueryPayment          = "query_payment"
+	OperationConsultPay            = "consult_pay"
+	OperationCancelOrder           = "cancel_order"
+	OperationRefundOrder           = "refund_order"
+	OperationQueryMerchantResource = "query_merchant_resource"
+	OperationOther                 = "other"
+)
+
+// Ledger writes used as the operation label of ledger write failures
+const (
+	LedgerCreateOrder  = "create_order"
+	LedgerUpdateOrder  = "update_order"
+	LedgerUpdateRefund = "update_refund"
+	LedgerDeleteRefund = "delete_refund"
+)
+
+// ResponseCodeTransportError labels DANA calls that failed before a response was received
+const ResponseCodeTransportError = "transport_error"
+
+// ResponseCodeNone labels DANA responses without a responseCode, e.g. non-JSON gateway errors
+const ResponseCodeNone = "none"
+
+// registry holds the application metrics, served by Handler
+var registry = prometheus.NewRegistry()
+
+var (
+	danaRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "dana",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of outbound DANA API calls by operation.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30},
+	}, []string{"operation"})
+
+	danaResponses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dana",
+		Name:      "responses_total",
+		Help:      "Outbound DANA API calls by operation, HTTP status class and DANA responseCode.",
+	}, []string{"operation", "status_class", "response_code"})
+
+	danaRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dana",
+		Name:      "retries_total",
+		Help:      "Outbound DANA API calls retried by operation.",
+	}, []string{"operation"})
+
+	danaCircuitState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "dana",
+		Name:      "circuit_breaker_state",
+		Help:      "1 for the current DANA circuit breaker state (closed, open or half_open), 0 otherwise.",
+	}, []string{"state"})
+
+	ordersCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dana",
+		Name:      "orders_created_total",
+		Help:      "Orders successfully created at DANA by checkout type.",
+	}, []string{"checkout_type"})
+
+	ordersPaid = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dana",
+		Name:      "orders_paid_total",
+		Help:      "Orders that reached SUCCESS by checkout type.",
+	}, []string{"checkout_type"})
+
+	ordersExpired = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dana",
+		Name:      "orders_expired_total",
+		Help:      "Orders that reached EXPIRED by checkout type.",
+	}, []string{"checkout_type"})
+
+	ledgerWriteFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "dana",
+		Name:      "ledger_write_failures_total",
+		Help:      "Ledger writes that failed after the DANA call succeeded, by operation.",
+	}, []string{"operation"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		danaRequestDuration,
+		danaResponses,
+		danaRetries,
+		danaCircuitState,
+		ordersCreated,
+		ordersPaid,
+		ordersExpired,
+		ledgerWriteFailures,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveDanaCall records the duration and outcome of an outbound DANA call
+// statusCode is 0 and responseCode is ResponseCodeTransportError when no response was received
+func ObserveDanaCall(operation string, statusCode int, responseCode string, duration time.Duration) {
+	if responseCode == "" {
+		responseCode = ResponseCodeNone
+	}
+	danaRequestDuration.WithLabelValues(operation).Observe(duration.Seconds())
+	danaResponses.WithLabelValues(operation, statusClass(statusCode), responseCode).Inc()
+}
+
+// DanaRetry counts a retried DANA call
+func DanaRetry(operation string) {
+	danaRetries.WithLabelValues(operation).Inc()
+}
+
+// SetCircuitState records the current DANA circuit breaker state
+func SetCircuitState(state string) {
+	for _, s := range []string{"closed", "open", "half_open"} {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		danaCircuitState.WithLabelValues(s).Set(value)
+	}
+}
+
+// OrderCreated counts an order created at DANA
+func OrderCreated(checkoutType string) {
+	ordersCreated.WithLabelValues(checkoutType).Inc()
+}
+
+// OrderPaid counts an order whose status changed to SUCCESS
+func OrderPaid(checkoutType string) {
+	ordersPaid.WithLabelValues(checkoutType).Inc()
+}
+
+// OrderExpired counts an order whose status changed to EXPIRED
+func OrderExpired(checkoutType string) {
+	ordersExpired.WithLabelValues(checkoutType).Inc()
+}
+
+// LedgerWriteFailed counts a ledger write that failed while the DANA call it records succeeded
+func LedgerWriteFailed(operation string) {
+	ledgerWriteFailures.WithLabelValues(operation).Inc()
+}
+
+// statusClass groups HTTP status codes as 2xx, 4xx, ... to bound label cardinality
+func statusClass(statusCode int) string {
+	switch {
+	case statusCode >= 500:
+		return "5xx"
+	case statusCode >= 400:
+		return "4xx"
+	case statusCode >= 300:
+		return "3xx"
+	case statusCode >= 200:
+		return "2xx"
+	case statusCode > 0:
+		return "1xx"
+	}
+	return "none"
+}
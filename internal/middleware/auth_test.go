@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riyanathariq/dana-enterprise/internal/auth"
+	"github.com/riyanathariq/dana-enterprise/internal/config"
+)
+
+// authServer serves POST /orders (orders:write) and GET /orders (orders:read) to clients behind Authenticate,
+// echoing the body and client ID each handler sees
+func authServer(t *testing.T, clients ...config.APIClient) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	authenticator, err := auth.NewAuthenticator(config.AuthConfig{Clients: clients, SignatureMaxSkew: 5 * time.Minute})
+	if err != nil {
+		t.Fatalf("NewAuthenticator: %v", err)
+	}
+
+	echo := func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			t.Errorf("handler read body: %v", err)
+		}
+		c.JSON(http.StatusOK, gin.H{"body": string(body), "client_id": auth.ClientID(c.Request.Context())})
+	}
+	r := gin.New()
+	orders := r.Group("/orders", Authenticate(authenticator))
+	orders.POST("", RequireScope(authenticator, auth.ScopeOrdersWrite), echo)
+	orders.GET("", RequireScope(authenticator, auth.ScopeOrdersRead), echo)
+	return r
+}
+
+// testClients are "shop" (key-shop, signs with secret-shop, orders:write) and "ops" (key-ops, orders:read)
+func testClients() []config.APIClient {
+	hash := func(key string) string {
+		sum := sha256.Sum256([]byte(key))
+		return hex.EncodeToString(sum[:])
+	}
+	return []config.APIClient{
+		{ID: "shop", APIKeySHA256: hash("key-shop"), HMACSecret: "secret-shop", Scopes: []string{auth.ScopeOrdersWrite}},
+		{ID: "ops", APIKeySHA256: hash("key-ops"), Scopes: []string{auth.ScopeOrdersRead}},
+	}
+}
+
+// authRequest builds a request with an API key, signed with secret unless it is empty
+func authRequest(method, target, body, apiKey, secret string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if apiKey != "" {
+		req.Header.Set(APIKeyHeader, apiKey)
+	}
+	if secret != "" {
+		timestamp := time.Now().Format(time.RFC3339)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(auth.StringToSign(method, req.URL.RequestURI(), []byte(body), timestamp)))
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	}
+	return req
+}
+
+// serve sends req to r and decodes the JSON response
+func serve(t *testing.T, r http.Handler, req *http.Request) (int, map[string]interface{}) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+	var body map[string]interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response %q: %v", recorder.Body.String(), err)
+	}
+	return recorder.Code, body
+}
+
+func TestAuthenticateAndRequireScope(t *testing.T) {
+	r := authServer(t, testClients()...)
+	const order = `{"partner_reference_no":"ORDER-001"}`
+
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+		wantCode   string
+	}{
+		{"missing API key", authRequest(http.MethodGet, "/orders", "", "", ""), http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"unknown API key", authRequest(http.MethodGet, "/orders", "", "key-unknown", ""), http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"missing scope", authRequest(http.MethodPost, "/orders", order, "key-ops", ""), http.StatusForbidden, "INSUFFICIENT_SCOPE"},
+		{"signing client without signature", authRequest(http.MethodPost, "/orders", order, "key-shop", ""), http.StatusUnauthorized, "INVALID_SIGNATURE"},
+		{"signing client with wrong secret", authRequest(http.MethodPost, "/orders", order, "key-shop", "secret-other"), http.StatusUnauthorized, "INVALID_SIGNATURE"},
+		{"signed request missing scope", authRequest(http.MethodGet, "/orders", "", "key-shop", "secret-shop"), http.StatusForbidden, "INSUFFICIENT_SCOPE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := serve(t, r, tt.req)
+			if status != tt.wantStatus || body["code"] != tt.wantCode {
+				t.Fatalf("status = %d, body = %v, want %d %s", status, body, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+
+	status, body := serve(t, r, authRequest(http.MethodGet, "/orders", "", "key-ops", ""))
+	if status != http.StatusOK || body["client_id"] != "ops" {
+		t.Errorf("key-only client: status = %d, body = %v", status, body)
+	}
+}
+
+func TestAuthenticateRestoresSignedBody(t *testing.T) {
+	r := authServer(t, testClients()...)
+	const order = `{"partner_reference_no":"ORDER-001"}`
+
+	status, body := serve(t, r, authRequest(http.MethodPost, "/orders", order, "key-shop", "secret-shop"))
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, body = %v", status, body)
+	}
+	if body["body"] != order || body["client_id"] != "shop" {
+		t.Errorf("handler saw body %q and client %v, want the signed body and shop", body["body"], body["client_id"])
+	}
+}
+
+func TestAuthenticateLimitsSignedBody(t *testing.T) {
+	r := authServer(t, testClients()...)
+
+	limit := strings.Repeat("a", maxSignedBodyBytes)
+	status, body := serve(t, r, authRequest(http.MethodPost, "/orders", limit, "key-shop", "secret-shop"))
+	if status != http.StatusOK || len(body["body"].(string)) != maxSignedBodyBytes {
+		t.Fatalf("body at the limit: status = %d, code = %v", status, body["code"])
+	}
+
+	status, body = serve(t, r, authRequest(http.MethodPost, "/orders", limit+"a", "key-shop", "secret-shop"))
+	if status != http.StatusRequestEntityTooLarge || body["code"] != "PAYLOAD_TOO_LARGE" {
+		t.Fatalf("body over the limit: status = %d, body = %v, want 413 PAYLOAD_TOO_LARGE", status, body)
+	}
+}
+
+func TestAuthenticateWithoutClients(t *testing.T) {
+	r := authServer(t)
+
+	status, body := serve(t, r, authRequest(http.MethodPost, "/orders", `{}`, "", ""))
+	if status != http.StatusOK || body["client_id"] != "" {
+		t.Fatalf("status = %d, body = %v, want the request let through anonymously", status, body)
+	}
+}
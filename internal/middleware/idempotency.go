@@ -0,0 +1,220 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riyanathariq/dana-enterprise/internal/model"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
+)
+
+// IdempotencyKeyHeader is the request header carrying the client idempotency key
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyReplayedHeader is set on responses replayed from a stored idempotent request
+const IdempotencyReplayedHeader = "Idempotency-Replayed"
+
+// keyLock is a mutex shared by in-flight requests with the same idempotency key
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// keyLocks serializes requests sharing the same idempotency key within this process
+var (
+	keyLocksMu sync.Mutex
+	keyLocks   = make(map[string]*keyLock)
+)
+
+// lockKey acquires the lock for key and returns its release function
+func lockKey(key string) func() {
+	keyLocksMu.Lock()
+	lock, ok := keyLocks[key]
+	if !ok {
+		lock = &keyLock{}
+		keyLocks[key] = lock
+	}
+	lock.refs++
+	keyLocksMu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+
+		keyLocksMu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(keyLocks, key)
+		}
+		keyLocksMu.Unlock()
+	}
+}
+
+// responseRecorder captures the response body written by the handler
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// requestHash fingerprints method, path and canonical body of a request
+func requestHash(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + ":" + path + ":"))
+	hash.Write(canonicalJSON(body))
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// canonicalJSON re-encodes a JSON body with sorted object keys and without whitespace, so retries that only
+// reorder fields or change formatting hash the same. Bodies that are not JSON are returned unchanged
+func canonicalJSON(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// Keep numbers as written, float64 would round large values
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return body
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return canonical
+}
+
+// idempotencyKeys returns the Idempotency-Key header and partner_reference_no natural key of a request
+// Keys are scoped to the authenticated client, so one client never replays the response stored for another
+func idempotencyKeys(c *gin.Context, body []byte) []string {
+	scope := ""
+	if clientID := c.GetString(ClientIDKey); clientID != "" {
+		scope = "client:" + clientID + ":"
+	}
+
+	var keys []string
+	if key := c.GetHeader(IdempotencyKeyHeader); key != "" {
+		keys = append(keys, scope+"key:"+key)
+	}
+
+	var naturalKey struct {
+		PartnerReferenceNo string `json:"partner_reference_no"`
+	}
+	if err := json.Unmarshal(body, &naturalKey); err == nil && naturalKey.PartnerReferenceNo != "" {
+		keys = append(keys, scope+"partner_reference_no:"+naturalKey.PartnerReferenceNo)
+	}
+
+	// Keys are always locked in the same order to avoid deadlocks
+	sort.Strings(keys)
+	return keys
+}
+
+// Idempotency replays the stored response for retried requests with the same key and payload,
+// and rejects requests reusing a key with a different payload with 409 Conflict
+// Only successful (2xx) responses are stored, failed requests can be retried
+// Stored responses expire after ttl, after which the key is processed as new
+func Idempotency(store repository.IdempotencyRepository, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, model.ErrorResponse{
+				Success: false,
+				Error:   err.Error(),
+				Code:    "VALIDATION_ERROR",
+				Details: "Invalid request body",
+			})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		keys := idempotencyKeys(c, body)
+		if len(keys) == 0 {
+			c.Next()
+			return
+		}
+		hash := requestHash(c.Request.Method, c.FullPath(), body)
+
+		for _, key := range keys {
+			unlock := lockKey(key)
+			defer unlock()
+		}
+
+		expiredBefore := time.Now().Add(-ttl)
+		for _, key := range keys {
+			record, err := store.GetIdempotency(c.Request.Context(), key)
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, model.ErrorResponse{
+					Success: false,
+					Error:   err.Error(),
+					Code:    "IDEMPOTENCY_ERROR",
+					Details: "Failed to look up idempotency key",
+				})
+				return
+			}
+			if record.CreatedAt.Before(expiredBefore) {
+				continue
+			}
+
+			if record.RequestHash != hash {
+				c.AbortWithStatusJSON(http.StatusConflict, model.ErrorResponse{
+					Success: false,
+					Error:   "idempotency key reused with a different request payload",
+					Code:    "IDEMPOTENCY_CONFLICT",
+					Details: "A different request was already processed with the same Idempotency-Key or partner_reference_no",
+				})
+				return
+			}
+
+			c.Header(IdempotencyReplayedHeader, "true")
+			c.Data(record.StatusCode, "application/json; charset=utf-8", record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if status < 200 || status >= 300 {
+			return
+		}
+
+		// Free expired keys, including the ones of this request that were ignored above
+		if err := store.DeleteExpiredIdempotency(c.Request.Context(), expiredBefore); err != nil {
+			slog.WarnContext(c.Request.Context(), "failed to delete expired idempotency keys", "error", err)
+		}
+		for _, key := range keys {
+			err := store.SaveIdempotency(c.Request.Context(), &repository.IdempotencyRecord{
+				Key:          key,
+				RequestHash:  hash,
+				StatusCode:   status,
+				ResponseBody: recorder.body.Bytes(),
+			})
+			if err != nil {
+				slog.WarnContext(c.Request.Context(), "failed to store idempotency key", "idempotency_key", key, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
+)
+
+// idempotentServer serves POST /order through Idempotency, counting the requests reaching the handler
+func idempotentServer(store repository.IdempotencyRepository, ttl time.Duration) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+	handled := 0
+	r := gin.New()
+	r.POST("/order", Idempotency(store, ttl), func(c *gin.Context) {
+		handled++
+		c.JSON(http.StatusCreated, gin.H{"success": true})
+	})
+	return r, &handled
+}
+
+func postOrder(r http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/order", strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, "key-1")
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestIdempotencyReplaysReorderedPayload(t *testing.T) {
+	r, handled := idempotentServer(repository.NewMemoryOrderRepository(), time.Hour)
+
+	first := postOrder(r, `{"partner_reference_no":"ORDER-001","amount":{"value":"10000.00","currency":"IDR"}}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first request: status = %d, want 201", first.Code)
+	}
+
+	retry := postOrder(r, `{
+		"amount": {"currency": "IDR", "value": "10000.00"},
+		"partner_reference_no": "ORDER-001"
+	}`)
+	if retry.Code != http.StatusCreated || retry.Header().Get(IdempotencyReplayedHeader) != "true" {
+		t.Fatalf("retry: status = %d, replayed = %q, want a replayed 201", retry.Code, retry.Header().Get(IdempotencyReplayedHeader))
+	}
+	if *handled != 1 {
+		t.Fatalf("handler ran %d times, want 1", *handled)
+	}
+
+	conflict := postOrder(r, `{"partner_reference_no":"ORDER-001","amount":{"value":"20000.00","currency":"IDR"}}`)
+	if conflict.Code != http.StatusConflict {
+		t.Fatalf("different payload: status = %d, want 409", conflict.Code)
+	}
+}
+
+func TestIdempotencyKeysExpire(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryOrderRepository()
+	r, handled := idempotentServer(store, time.Hour)
+
+	// A day-old response for the same key, with a different payload
+	for _, key := range []string{"key:key-1", "partner_reference_no:ORDER-001"} {
+		err := store.SaveIdempotency(ctx, &repository.IdempotencyRecord{
+			Key:          key,
+			RequestHash:  "stale",
+			StatusCode:   http.StatusCreated,
+			ResponseBody: []byte(`{"success":true}`),
+			CreatedAt:    time.Now().Add(-24 * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("SaveIdempotency: %v", err)
+		}
+	}
+
+	body := `{"partner_reference_no":"ORDER-001"}`
+	if recorder := postOrder(r, body); recorder.Code != http.StatusCreated || recorder.Header().Get(IdempotencyReplayedHeader) != "" {
+		t.Fatalf("expired key: status = %d, replayed = %q, want a fresh 201", recorder.Code, recorder.Header().Get(IdempotencyReplayedHeader))
+	}
+	if recorder := postOrder(r, body); recorder.Header().Get(IdempotencyReplayedHeader) != "true" {
+		t.Fatalf("retry after expiry: want the new response replayed")
+	}
+	if *handled != 1 {
+		t.Fatalf("handler ran %d times, want 1", *handled)
+	}
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/riyanathariq/dana-enterprise/internal/config"
+	"github.com/riyanathariq/dana-enterprise/internal/ratelimit"
+)
+
+// testClientHeader stands in for Authenticate, setting the client ID of a request in tests
+const testClientHeader = "X-Test-Client"
+
+// failingStore is a ratelimit.Store whose backend is down
+type failingStore struct{}
+
+func (failingStore) Allow(context.Context, string, ratelimit.Limit, time.Time) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store unavailable")
+}
+
+// limitedServer serves GET /orders limited as the orders group, at 30 requests per minute with a burst of 2,
+// with the clock of the limiter set by *now
+func limitedServer(store ratelimit.Store, enabled bool) (*gin.Engine, *time.Time) {
+	gin.SetMode(gin.TestMode)
+	now := time.Date(2025, 11, 5, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(store, config.RateLimitConfig{
+		Enabled: enabled,
+		Orders:  config.RateLimitRule{RequestsPerMinute: 30, Burst: 2},
+	})
+	limiter.now = func() time.Time { return now }
+
+	r := gin.New()
+	r.GET("/orders", func(c *gin.Context) {
+		if clientID := c.GetHeader(testClientHeader); clientID != "" {
+			c.Set(ClientIDKey, clientID)
+		}
+	}, limiter.Limit(config.RateLimitOrders), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	})
+	return r, &now
+}
+
+// getOrders sends GET /orders from remoteAddr as clientID, anonymous when clientID is empty
+func getOrders(r http.Handler, remoteAddr, clientID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.RemoteAddr = remoteAddr
+	if clientID != "" {
+		req.Header.Set(testClientHeader, clientID)
+	}
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestLimitHeaders(t *testing.T) {
+	r, now := limitedServer(ratelimit.NewMemoryStore(), true)
+	start := *now
+
+	tests := []struct {
+		name       string
+		at         time.Duration
+		wantStatus int
+		remaining  string
+		reset      string
+		retryAfter string
+	}{
+		{"first", 0, http.StatusOK, "1", "2", ""},
+		{"burst exhausted", 0, http.StatusOK, "0", "4", ""},
+		{"over the burst", 0, http.StatusTooManyRequests, "0", "4", "2"},
+		{"seconds round up", 1500 * time.Millisecond, http.StatusTooManyRequests, "0", "3", "1"},
+		{"one token refilled", 2 * time.Second, http.StatusOK, "0", "4", ""},
+	}
+	for _, tt := range tests {
+		*now = start.Add(tt.at)
+		recorder := getOrders(r, "192.0.2.1:1234", "shop")
+		if recorder.Code != tt.wantStatus {
+			t.Fatalf("%s: status = %d, want %d", tt.name, recorder.Code, tt.wantStatus)
+		}
+		headers := map[string]string{
+			RateLimitLimitHeader:     "2",
+			RateLimitRemainingHeader: tt.remaining,
+			RateLimitResetHeader:     tt.reset,
+			RetryAfterHeader:         tt.retryAfter,
+		}
+		for header, want := range headers {
+			if got := recorder.Header().Get(header); got != want {
+				t.Errorf("%s: %s = %q, want %q", tt.name, header, got, want)
+			}
+		}
+	}
+}
+
+func TestLimitKeysByClientOrIP(t *testing.T) {
+	r, _ := limitedServer(ratelimit.NewMemoryStore(), true)
+
+	// shop uses its burst from two addresses
+	for _, addr := range []string{"192.0.2.1:1234", "192.0.2.2:1234"} {
+		if code := getOrders(r, addr, "shop").Code; code != http.StatusOK {
+			t.Fatalf("shop from %s: status = %d, want 200", addr, code)
+		}
+	}
+	tests := []struct {
+		name       string
+		remoteAddr string
+		clientID   string
+		wantStatus int
+	}{
+		{"same client from a new address", "192.0.2.3:1234", "shop", http.StatusTooManyRequests},
+		{"other client from the same address", "192.0.2.1:1234", "ops", http.StatusOK},
+		{"anonymous from the same address", "192.0.2.1:1234", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if code := getOrders(r, tt.remoteAddr, tt.clientID).Code; code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, code, tt.wantStatus)
+		}
+	}
+
+	// Anonymous requests share the bucket of their IP
+	getOrders(r, "192.0.2.1:5678", "")
+	if code := getOrders(r, "192.0.2.1:9012", "").Code; code != http.StatusTooManyRequests {
+		t.Errorf("third anonymous request from 192.0.2.1: status = %d, want 429", code)
+	}
+	if code := getOrders(r, "192.0.2.4:1234", "").Code; code != http.StatusOK {
+		t.Errorf("anonymous request from another IP: status = %d, want 200", code)
+	}
+}
+
+func TestLimitFailsOpenOnStoreError(t *testing.T) {
+	r, _ := limitedServer(failingStore{}, true)
+
+	for i := range 3 {
+		recorder := getOrders(r, "192.0.2.1:1234", "shop")
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200 while the store is down", i+1, recorder.Code)
+		}
+		if got := recorder.Header().Get(RateLimitLimitHeader); got != "" {
+			t.Errorf("request %d: %s = %q, want no rate limit headers", i+1, RateLimitLimitHeader, got)
+		}
+	}
+}
+
+func TestLimitDisabled(t *testing.T) {
+	r, _ := limitedServer(ratelimit.NewMemoryStore(), false)
+
+	for i := range 3 {
+		if code := getOrders(r, "192.0.2.1:1234", "shop").Code; code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200 with rate limits disabled", i+1, code)
+		}
+	}
+}
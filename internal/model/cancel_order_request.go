@@ -0,0 +1,8 @@
+package model
+
+// CancelOrderRequest represents the HTTP request body for cancelling an order
+type CancelOrderRequest struct {
+	MerchantID string        `json:"merchant_id,omitempty"`
+	Reason     string        `json:"reason" binding:"required"`
+	Amount     *MoneyRequest `json:"amount,omitempty"`
+}
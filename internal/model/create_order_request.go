@@ -22,8 +22,8 @@ type MoneyRequest struct {
 
 // PayOptionDetailRequest represents payment option details
 type PayOptionDetailRequest struct {
-	PayMethod     string        `json:"pay_method" binding:"required"`
-	PayOption     string        `json:"pay_option" binding:"required"`
+	PayMethod     PayMethod     `json:"pay_method" binding:"required"`
+	PayOption     PayOption     `json:"pay_option"` // Empty for pay methods without options, see PayOptions
 	TransAmount   MoneyRequest  `json:"trans_amount" binding:"required"`
 	FeeAmount     *MoneyRequest `json:"fee_amount,omitempty"`
 	CardToken     *string       `json:"card_token,omitempty"`
@@ -0,0 +1,25 @@
+package model
+
+// CreateOrderResponse represents a newly created order
+type CreateOrderResponse struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message"`
+	Data    *CreateOrderData `json:"data"`
+}
+
+// CreateOrderData holds what the frontend needs to send the buyer to pay an order
+// Depending on the checkout type and pay option, the buyer pays through redirect_url, qr_payload or va_number
+type CreateOrderData struct {
+	PartnerReferenceNo string       `json:"partner_reference_no"`
+	ReferenceNo        string       `json:"reference_no,omitempty"` // DANA reference number
+	MerchantID         string       `json:"merchant_id"`
+	CheckoutType       string       `json:"checkout_type"`
+	Amount             MoneyRequest `json:"amount"`
+	PayMethod          PayMethod    `json:"pay_method,omitempty"` // Custom checkout only
+	PayOption          PayOption    `json:"pay_option,omitempty"` // Custom checkout only
+	RedirectURL        string       `json:"redirect_url,omitempty"`
+	PaymentCode        string       `json:"payment_code,omitempty"` // Payment code as sent by DANA
+	QRPayload          string       `json:"qr_payload,omitempty"`   // QRIS content to render as a QR code
+	VANumber           string       `json:"va_number,omitempty"`    // Virtual account number to transfer to
+	ExpiresAt          string       `json:"expires_at,omitempty"`
+}
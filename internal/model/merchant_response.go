@@ -39,13 +39,22 @@ type MerchantResource struct {
 type MerchantInfoMeta struct {
 	RequestID   string `json:"request_id,omitempty"`
 	Timestamp   string `json:"timestamp,omitempty"`
+	CachedAt    string `json:"cached_at,omitempty"` // Time the balances were queried at DANA, older than timestamp when served from cache
 	Environment string `json:"environment,omitempty"`
 }
 
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error"`
-	Code    string `json:"code,omitempty"`
-	Details string `json:"details,omitempty"`
+	Success bool         `json:"success"`
+	Error   string       `json:"error"`
+	Code    string       `json:"code,omitempty"`
+	Details string       `json:"details,omitempty"`
+	Errors  []FieldError `json:"errors,omitempty"` // Every invalid field, for request validation errors
+}
+
+// FieldError describes why one field of a request is invalid
+type FieldError struct {
+	Field   string `json:"field"` // JSON path, e.g. pay_option_details[0].trans_amount.value
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
@@ -0,0 +1,33 @@
+package model
+
+// OrderListResponse represents a page of stored orders
+type OrderListResponse struct {
+	Success bool           `json:"success"`
+	Message string         `json:"message"`
+	Data    []*OrderData   `json:"data"`
+	Meta    *OrderListMeta `json:"meta,omitempty"`
+}
+
+// OrderData represents an order recorded in the local ledger
+type OrderData struct {
+	PartnerReferenceNo string       `json:"partner_reference_no"`
+	MerchantID         string       `json:"merchant_id"`
+	SubMerchantID      string       `json:"sub_merchant_id,omitempty"`
+	ExternalStoreID    string       `json:"external_store_id,omitempty"`
+	Amount             MoneyRequest `json:"amount"`
+	CheckoutType       string       `json:"checkout_type"`
+	ReferenceNo        string       `json:"reference_no,omitempty"`
+	WebRedirectURL     string       `json:"web_redirect_url,omitempty"`
+	Status             string       `json:"status"`
+	ValidUpTo          string       `json:"valid_up_to,omitempty"`
+	ClientID           string       `json:"client_id,omitempty"`
+	CreatedAt          string       `json:"created_at"`
+	UpdatedAt          string       `json:"updated_at"`
+}
+
+// OrderListMeta contains pagination metadata
+type OrderListMeta struct {
+	Count      int    `json:"count"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,109 @@
+package model
+
+import "slices"
+
+// PayMethod is a DANA payment method, sent as payMethod
+type PayMethod string
+
+// Pay methods supported for custom checkout
+const (
+	PayMethodBalance        PayMethod = "BALANCE"
+	PayMethodVirtualAccount PayMethod = "VIRTUAL_ACCOUNT"
+	PayMethodNetworkPay     PayMethod = "NETWORK_PAY"
+)
+
+// PayOption is a DANA payment option of a pay method, sent as payOption
+type PayOption string
+
+// Pay options supported for custom checkout
+const (
+	PayOptionNone PayOption = "" // Pay methods without options, such as BALANCE
+
+	PayOptionVirtualAccountBCA     PayOption = "VIRTUAL_ACCOUNT_BCA"
+	PayOptionVirtualAccountBNI     PayOption = "VIRTUAL_ACCOUNT_BNI"
+	PayOptionVirtualAccountMandiri PayOption = "VIRTUAL_ACCOUNT_MANDIRI"
+	PayOptionVirtualAccountBRI     PayOption = "VIRTUAL_ACCOUNT_BRI"
+	PayOptionVirtualAccountBTPN    PayOption = "VIRTUAL_ACCOUNT_BTPN"
+	PayOptionVirtualAccountCIMB    PayOption = "VIRTUAL_ACCOUNT_CIMB"
+	PayOptionVirtualAccountPermata PayOption = "VIRTUAL_ACCOUNT_PERMATA"
+
+	PayOptionQRIS      PayOption = "NETWORK_PAY_PG_QRIS"
+	PayOptionOVO       PayOption = "NETWORK_PAY_PG_OVO"
+	PayOptionGoPay     PayOption = "NETWORK_PAY_PG_GOPAY"
+	PayOptionLinkAja   PayOption = "NETWORK_PAY_PG_LINKAJA"
+	PayOptionShopeePay PayOption = "NETWORK_PAY_PG_SPAY"
+	PayOptionCard      PayOption = "NETWORK_PAY_PG_CARD"
+)
+
+// PayOptionCategory groups pay options for display
+type PayOptionCategory string
+
+// Pay option categories
+const (
+	PayOptionCategoryBalance        PayOptionCategory = "balance"
+	PayOptionCategoryVirtualAccount PayOptionCategory = "virtual_account"
+	PayOptionCategoryQRIS           PayOptionCategory = "qris"
+	PayOptionCategoryEWallet        PayOptionCategory = "ewallet"
+	PayOptionCategoryCard           PayOptionCategory = "card"
+)
+
+// PayOptionInfo describes a supported pay method and pay option pair
+type PayOptionInfo struct {
+	PayMethod             PayMethod         `json:"pay_method"`
+	PayOption             PayOption         `json:"pay_option,omitempty"`
+	Category              PayOptionCategory `json:"category"`
+	DisplayName           string            `json:"display_name"`
+	RequiresCardToken     bool              `json:"requires_card_token"`     // card_token must be sent
+	RequiresMerchantToken bool              `json:"requires_merchant_token"` // merchant_token of the buyer's bound DANA account must be sent
+}
+
+// payOptionCatalogue lists every pay method and pay option pair accepted for custom checkout
+var payOptionCatalogue = []PayOptionInfo{
+	{PayMethod: PayMethodBalance, PayOption: PayOptionNone, Category: PayOptionCategoryBalance, DisplayName: "DANA Balance", RequiresMerchantToken: true},
+
+	{PayMethod: PayMethodVirtualAccount, PayOption: PayOptionVirtualAccountBCA, Category: PayOptionCategoryVirtualAccount, DisplayName: "BCA Virtual Account"},
+	{PayMethod: PayMethodVirtualAccount, PayOption: PayOptionVirtualAccountBNI, Category: PayOptionCategoryVirtualAccount, DisplayName: "BNI Virtual Account"},
+	{PayMethod: PayMethodVirtualAccount, PayOption: PayOptionVirtualAccountMandiri, Category: PayOptionCategoryVirtualAccount, DisplayName: "Mandiri Virtual Account"},
+	{PayMethod: PayMethodVirtualAccount, PayOption: PayOptionVirtualAccountBRI, Category: PayOptionCategoryVirtualAccount, DisplayName: "BRI Virtual Account"},
+	{PayMethod: PayMethodVirtualAccount, PayOption: PayOptionVirtualAccountBTPN, Category: PayOptionCategoryVirtualAccount, DisplayName: "BTPN Virtual Account"},
+	{PayMethod: PayMethodVirtualAccount, PayOption: PayOptionVirtualAccountCIMB, Category: PayOptionCategoryVirtualAccount, DisplayName: "CIMB Niaga Virtual Account"},
+	{PayMethod: PayMethodVirtualAccount, PayOption: PayOptionVirtualAccountPermata, Category: PayOptionCategoryVirtualAccount, DisplayName: "Permata Virtual Account"},
+
+	{PayMethod: PayMethodNetworkPay, PayOption: PayOptionQRIS, Category: PayOptionCategoryQRIS, DisplayName: "QRIS"},
+
+	{PayMethod: PayMethodNetworkPay, PayOption: PayOptionOVO, Category: PayOptionCategoryEWallet, DisplayName: "OVO"},
+	{PayMethod: PayMethodNetworkPay, PayOption: PayOptionGoPay, Category: PayOptionCategoryEWallet, DisplayName: "GoPay"},
+	{PayMethod: PayMethodNetworkPay, PayOption: PayOptionLinkAja, Category: PayOptionCategoryEWallet, DisplayName: "LinkAja"},
+	{PayMethod: PayMethodNetworkPay, PayOption: PayOptionShopeePay, Category: PayOptionCategoryEWallet, DisplayName: "ShopeePay"},
+
+	{PayMethod: PayMethodNetworkPay, PayOption: PayOptionCard, Category: PayOptionCategoryCard, DisplayName: "Credit / Debit Card", RequiresCardToken: true},
+}
+
+// PayOptions returns the catalogue of supported pay method and pay option pairs
+func PayOptions() []PayOptionInfo {
+	return slices.Clone(payOptionCatalogue)
+}
+
+// LookupPayOption returns the catalogue entry of a pay method and pay option pair
+func LookupPayOption(payMethod PayMethod, payOption PayOption) (PayOptionInfo, bool) {
+	for _, info := range payOptionCatalogue {
+		if info.PayMethod == payMethod && info.PayOption == payOption {
+			return info, true
+		}
+	}
+	return PayOptionInfo{}, false
+}
+
+// Known reports whether the pay method is in the catalogue
+func (m PayMethod) Known() bool {
+	return slices.ContainsFunc(payOptionCatalogue, func(info PayOptionInfo) bool {
+		return info.PayMethod == m
+	})
+}
+
+// PayOptionListResponse represents the catalogue of supported pay options
+type PayOptionListResponse struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Data    []PayOptionInfo `json:"data"`
+}
@@ -0,0 +1,26 @@
+package model
+
+// PaymentMethodListResponse represents the payment methods available to an order
+type PaymentMethodListResponse struct {
+	Success bool                 `json:"success"`
+	Message string               `json:"message"`
+	Data    []*PaymentMethodData `json:"data"`
+	Meta    *PaymentMethodMeta   `json:"meta,omitempty"`
+}
+
+// PaymentMethodData represents a pay method and pay option pair
+type PaymentMethodData struct {
+	PayMethod string        `json:"pay_method"`
+	PayOption string        `json:"pay_option,omitempty"`
+	Fee       *MoneyRequest `json:"fee,omitempty"`
+	MinAmount *MoneyRequest `json:"min_amount,omitempty"`
+	MaxAmount *MoneyRequest `json:"max_amount,omitempty"`
+}
+
+// PaymentMethodMeta contains metadata about the consultation
+type PaymentMethodMeta struct {
+	MerchantID      string       `json:"merchant_id"`
+	ConsultedAmount MoneyRequest `json:"consulted_amount"` // Amount DANA was consulted with, from another request in the same amount band when cached
+	Cached          bool         `json:"cached"`
+	FetchedAt       string       `json:"fetched_at"`
+}
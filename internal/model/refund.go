@@ -0,0 +1,22 @@
+package model
+
+// RefundOrderRequest represents the HTTP request body for refunding an order
+type RefundOrderRequest struct {
+	PartnerRefundNo string       `json:"partner_refund_no" binding:"required"`
+	MerchantID      string       `json:"merchant_id,omitempty"`
+	Amount          MoneyRequest `json:"amount" binding:"required"`
+	Reason          string       `json:"reason,omitempty"`
+}
+
+// RefundData represents a refund in API responses
+type RefundData struct {
+	PartnerRefundNo    string       `json:"partner_refund_no"`
+	PartnerReferenceNo string       `json:"partner_reference_no"`
+	MerchantID         string       `json:"merchant_id"`
+	RefundNo           string       `json:"refund_no,omitempty"`
+	Amount             MoneyRequest `json:"amount"`
+	Reason             string       `json:"reason,omitempty"`
+	Status             string       `json:"status"`
+	RefundTime         string       `json:"refund_time,omitempty"`
+	CreatedAt          string       `json:"created_at"`
+}
@@ -0,0 +1,7 @@
+package model
+
+// WebhookAckResponse represents the SNAP-compliant acknowledgement returned to DANA webhooks
+type WebhookAckResponse struct {
+	ResponseCode    string `json:"responseCode"`
+	ResponseMessage string `json:"responseMessage"`
+}
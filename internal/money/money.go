@@ -0,0 +1,175 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/dana-id/dana-go/payment_gateway/v1"
+)
+
+// IDR is the only currency DANA settles in
+const IDR = "IDR"
+
+// scales holds the number of decimal places of each supported currency
+// DANA requires IDR values with exactly 2 decimal places, e.g. "10000.00"
+var scales = map[string]int{
+	IDR: 2,
+}
+
+var (
+	// ErrInvalidAmount is returned for amount values that are malformed, negative, too precise or too large
+	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrUnsupportedCurrency is returned for currencies without a known scale
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	// ErrCurrencyMismatch is returned when combining amounts of different currencies
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
+
+// Money is an exact, non-negative amount held as an integer number of minor units
+// The zero value is not a valid amount, create one with New or Parse
+type Money struct {
+	minor    int64
+	currency string
+}
+
+// New creates an amount of minor units, e.g. New(1000000, "IDR") is IDR 10000.00
+func New(minor int64, currency string) (Money, error) {
+	if _, ok := scales[currency]; !ok {
+		return Money{}, fmt.Errorf("%w %q", ErrUnsupportedCurrency, currency)
+	}
+	if minor < 0 {
+		return Money{}, fmt.Errorf("%w: %d is negative", ErrInvalidAmount, minor)
+	}
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// Parse parses a decimal amount value such as "10000" or "10000.50"
+// It rejects signs, exponents, whitespace and more decimal places than the currency has, rather than rounding
+func Parse(value, currency string) (Money, error) {
+	scale, ok := scales[currency]
+	if !ok {
+		return Money{}, fmt.Errorf("%w %q", ErrUnsupportedCurrency, currency)
+	}
+
+	intPart, decimalPart, hasPoint := strings.Cut(value, ".")
+	if intPart == "" || !isDigits(intPart) || (hasPoint && (decimalPart == "" || !isDigits(decimalPart))) {
+		if strings.HasPrefix(value, "-") {
+			return Money{}, fmt.Errorf("%w: %q is negative", ErrInvalidAmount, value)
+		}
+		return Money{}, fmt.Errorf("%w: %q is not a decimal number", ErrInvalidAmount, value)
+	}
+	if len(decimalPart) > scale {
+		return Money{}, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidAmount, value, scale)
+	}
+
+	// Pad the decimals to the scale, so "10.5" is read as 1050 minor units
+	digits := intPart + decimalPart + strings.Repeat("0", scale-len(decimalPart))
+	var minor int64
+	for _, c := range digits {
+		d := int64(c - '0')
+		if minor > (math.MaxInt64-d)/10 {
+			return Money{}, fmt.Errorf("%w: %q is too large", ErrInvalidAmount, value)
+		}
+		minor = minor*10 + d
+	}
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// MustParse is like Parse but panics on invalid input, for amounts fixed in code
+func MustParse(value, currency string) Money {
+	m, err := Parse(value, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// FromGateway parses an amount of the DANA payment gateway API
+func FromGateway(m payment_gateway.Money) (Money, error) {
+	return Parse(m.Value, m.Currency)
+}
+
+// Gateway converts the amount to the DANA payment gateway format
+func (m Money) Gateway() payment_gateway.Money {
+	return payment_gateway.Money{Value: m.String(), Currency: m.currency}
+}
+
+// Minor returns the amount in minor units
+func (m Money) Minor() int64 {
+	return m.minor
+}
+
+// Currency returns the ISO 4217 currency code
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+// String formats the value with exactly the currency's decimal places, e.g. "10000.00"
+func (m Money) String() string {
+	scale := scales[m.currency]
+	if scale == 0 {
+		return fmt.Sprintf("%d", m.minor)
+	}
+	unit := int64(math.Pow10(scale))
+	return fmt.Sprintf("%d.%0*d", m.minor/unit, scale, m.minor%unit)
+}
+
+// Add returns the sum of two amounts of the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	if m.minor > math.MaxInt64-other.minor {
+		return Money{}, fmt.Errorf("%w: %s + %s is too large", ErrInvalidAmount, m, other)
+	}
+	return Money{minor: m.minor + other.minor, currency: m.currency}, nil
+}
+
+// Sub returns the difference of two amounts of the same currency, which must not be negative
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	if other.minor > m.minor {
+		return Money{}, fmt.Errorf("%w: %s - %s is negative", ErrInvalidAmount, m, other)
+	}
+	return Money{minor: m.minor - other.minor, currency: m.currency}, nil
+}
+
+// Cmp compares two amounts of the same currency, returning -1, 0 or +1
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.minor < other.minor:
+		return -1, nil
+	case m.minor > other.minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.currency != other.currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+	return nil
+}
+
+// isDigits reports whether s consists of ASCII digits only
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
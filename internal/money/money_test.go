@@ -0,0 +1,171 @@
+package money
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dana-id/dana-go/payment_gateway/v1"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		currency  string
+		wantMinor int64
+		wantErr   error
+	}{
+		{"whole", "10000", IDR, 1000000, nil},
+		{"two decimals", "10000.50", IDR, 1000050, nil},
+		{"short fraction", "10000.5", IDR, 1000050, nil},
+		{"zero", "0.00", IDR, 0, nil},
+		{"largest", "92233720368547758.07", IDR, 9223372036854775807, nil},
+		{"excess precision", "100.999", IDR, 0, ErrInvalidAmount},
+		{"negative", "-100.00", IDR, 0, ErrInvalidAmount},
+		{"plus sign", "+100.00", IDR, 0, ErrInvalidAmount},
+		{"missing fraction", "100.", IDR, 0, ErrInvalidAmount},
+		{"missing integer", ".50", IDR, 0, ErrInvalidAmount},
+		{"empty", "", IDR, 0, ErrInvalidAmount},
+		{"non-numeric", "abc", IDR, 0, ErrInvalidAmount},
+		{"exponent", "1e5", IDR, 0, ErrInvalidAmount},
+		{"whitespace", " 100.00", IDR, 0, ErrInvalidAmount},
+		{"thousands separator", "10,000.00", IDR, 0, ErrInvalidAmount},
+		{"overflow", "92233720368547758.08", IDR, 0, ErrInvalidAmount},
+		{"int64 overflow", "9223372036854775808", IDR, 0, ErrInvalidAmount},
+		{"unsupported currency", "100.00", "USD", 0, ErrUnsupportedCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse(tt.value, tt.currency)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Parse(%q) error = %v, want %v", tt.value, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.value, err)
+			}
+			if m.Minor() != tt.wantMinor || m.Currency() != tt.currency {
+				t.Errorf("Parse(%q) = %d %s, want %d %s", tt.value, m.Minor(), m.Currency(), tt.wantMinor, tt.currency)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(-1, IDR); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("New(-1) error = %v, want %v", err, ErrInvalidAmount)
+	}
+	if _, err := New(100, "USD"); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("New(100, USD) error = %v, want %v", err, ErrUnsupportedCurrency)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		minor int64
+		want  string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{50, "0.50"},
+		{100, "1.00"},
+		{1000050, "10000.50"},
+		{9223372036854775807, "92233720368547758.07"},
+	}
+	for _, tt := range tests {
+		m, err := New(tt.minor, IDR)
+		if err != nil {
+			t.Fatalf("New(%d): %v", tt.minor, err)
+		}
+		if got := m.String(); got != tt.want {
+			t.Errorf("New(%d).String() = %q, want %q", tt.minor, got, tt.want)
+		}
+	}
+}
+
+func TestAddSub(t *testing.T) {
+	largest := MustParse("92233720368547758.07", IDR)
+	one := MustParse("0.01", IDR)
+	ten := MustParse("10.00", IDR)
+	other := Money{minor: 100, currency: "USD"}
+
+	sum, err := ten.Add(one)
+	if err != nil || sum.String() != "10.01" {
+		t.Errorf("10.00 + 0.01 = %s, %v, want 10.01", sum, err)
+	}
+	if sum, err := largest.Add(Money{minor: 0, currency: IDR}); err != nil || sum != largest {
+		t.Errorf("largest + 0 = %s, %v, want %s", sum, err, largest)
+	}
+	if _, err := largest.Add(one); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("largest + 0.01 error = %v, want %v", err, ErrInvalidAmount)
+	}
+
+	diff, err := ten.Sub(one)
+	if err != nil || diff.String() != "9.99" {
+		t.Errorf("10.00 - 0.01 = %s, %v, want 9.99", diff, err)
+	}
+	if diff, err := ten.Sub(ten); err != nil || !diff.IsZero() {
+		t.Errorf("10.00 - 10.00 = %s, %v, want zero", diff, err)
+	}
+	if _, err := one.Sub(ten); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("0.01 - 10.00 error = %v, want %v", err, ErrInvalidAmount)
+	}
+
+	if _, err := ten.Add(other); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add across currencies error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+	if _, err := ten.Sub(other); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sub across currencies error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+	if _, err := ten.Cmp(other); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Cmp across currencies error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+}
+
+func TestCmp(t *testing.T) {
+	one := MustParse("0.01", IDR)
+	ten := MustParse("10.00", IDR)
+	tests := []struct {
+		a, b Money
+		want int
+	}{
+		{one, ten, -1},
+		{ten, one, 1},
+		{ten, MustParse("10", IDR), 0},
+	}
+	for _, tt := range tests {
+		got, err := tt.a.Cmp(tt.b)
+		if err != nil || got != tt.want {
+			t.Errorf("%s.Cmp(%s) = %d, %v, want %d", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+}
+
+func TestGatewayRoundTrip(t *testing.T) {
+	for _, value := range []string{"0.00", "0.05", "10000.50", "92233720368547758.07"} {
+		m, err := FromGateway(payment_gateway.Money{Value: value, Currency: IDR})
+		if err != nil {
+			t.Fatalf("FromGateway(%s): %v", value, err)
+		}
+		if got := m.Gateway(); got.Value != value || got.Currency != IDR {
+			t.Errorf("FromGateway(%s).Gateway() = %+v", value, got)
+		}
+	}
+
+	// Values DANA sends with fewer decimals are normalised to the currency's scale
+	m, err := FromGateway(payment_gateway.Money{Value: "10000", Currency: IDR})
+	if err != nil {
+		t.Fatalf("FromGateway(10000): %v", err)
+	}
+	if got := m.Gateway(); got.Value != "10000.00" || got.Currency != IDR {
+		t.Errorf("FromGateway(10000).Gateway() = %+v, want 10000.00 IDR", got)
+	}
+
+	for _, gm := range []payment_gateway.Money{{Value: "100.999", Currency: IDR}, {Value: "100.00", Currency: "USD"}, {Value: "", Currency: IDR}} {
+		if _, err := FromGateway(gm); err == nil {
+			t.Errorf("FromGateway(%+v) succeeded, want an error", gm)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreBurstAndRefill(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	limit := Limit{Rate: 0.5, Burst: 2} // 30 requests per minute
+	start := time.Date(2025, 11, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		at         time.Duration
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+		reset      time.Duration
+	}{
+		{"first", 0, true, 1, 0, 2 * time.Second},
+		{"burst exhausted", 0, true, 0, 0, 4 * time.Second},
+		{"over the burst", 0, false, 0, 2 * time.Second, 4 * time.Second},
+		{"half a token refilled", time.Second, false, 0, time.Second, 3 * time.Second},
+		{"one token refilled", 2 * time.Second, true, 0, 0, 4 * time.Second},
+		{"refilled to the burst only", time.Minute, true, 1, 0, 2 * time.Second},
+	}
+	for _, tt := range tests {
+		result, err := store.Allow(ctx, "client:shop", limit, start.Add(tt.at))
+		if err != nil {
+			t.Fatalf("%s: Allow: %v", tt.name, err)
+		}
+		want := Result{Allowed: tt.allowed, Limit: 2, Remaining: tt.remaining, RetryAfter: tt.retryAfter, Reset: tt.reset}
+		if result != want {
+			t.Errorf("%s: Allow = %+v, want %+v", tt.name, result, want)
+		}
+	}
+}
+
+func TestMemoryStoreKeysAreIndependent(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	limit := Limit{Rate: 1, Burst: 1}
+	now := time.Date(2025, 11, 5, 12, 0, 0, 0, time.UTC)
+
+	if result, _ := store.Allow(ctx, "client:shop", limit, now); !result.Allowed {
+		t.Fatalf("first request of shop denied")
+	}
+	if result, _ := store.Allow(ctx, "client:shop", limit, now); result.Allowed {
+		t.Fatalf("second request of shop allowed past the burst")
+	}
+	if result, _ := store.Allow(ctx, "client:ops", limit, now); !result.Allowed {
+		t.Errorf("ops denied by the bucket of shop")
+	}
+}
+
+func TestMemoryStoreSweepsIdleBuckets(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	fast := Limit{Rate: 1, Burst: 2}
+	slow := Limit{Rate: 1.0 / 600, Burst: 1} // Refills in 10 minutes
+	start := time.Date(2025, 11, 5, 12, 0, 0, 0, time.UTC)
+
+	store.Allow(ctx, "idle", fast, start)
+	store.Allow(ctx, "slow", slow, start)
+	store.Allow(ctx, "recent", Limit{Rate: 0.1, Burst: 2}, start.Add(sweepInterval-time.Second))
+	if n := len(store.buckets); n != 3 {
+		t.Fatalf("buckets before the sweep interval = %d, want 3", n)
+	}
+
+	// "idle" has refilled and is dropped; "slow" and "recent", used a second ago, have not refilled yet
+	store.Allow(ctx, "new", fast, start.Add(sweepInterval))
+	for key, want := range map[string]bool{"idle": false, "slow": true, "recent": true, "new": true} {
+		if _, ok := store.buckets[key]; ok != want {
+			t.Errorf("bucket %s kept = %v, want %v", key, ok, want)
+		}
+	}
+
+	// A dropped bucket comes back full
+	result, _ := store.Allow(ctx, "idle", fast, start.Add(sweepInterval))
+	if !result.Allowed || result.Remaining != 1 {
+		t.Errorf("Allow after sweep = %+v, want a full bucket", result)
+	}
+}
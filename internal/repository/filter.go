@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Pagination limits for List
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// OrderFilter narrows the orders returned by List, zero values are ignored
+type OrderFilter struct {
+	Status          string
+	Statuses        []string // any of, combined with Status
+	MerchantID      string
+	SubMerchantID   string
+	ExternalStoreID string
+	ClientID        string
+	CreatedFrom     *time.Time // inclusive
+	CreatedTo       *time.Time // exclusive
+	MinAmount       *int64     // inclusive, in minor units
+	MaxAmount       *int64     // inclusive, in minor units
+	Cursor          string
+	Limit           int
+}
+
+// normalizedLimit returns the page size clamped to the allowed range
+func (f OrderFilter) normalizedLimit() int {
+	if f.Limit <= 0 {
+		return DefaultListLimit
+	}
+	if f.Limit > MaxListLimit {
+		return MaxListLimit
+	}
+	return f.Limit
+}
+
+// listCursor identifies the last order of a page in (created_at, partner_reference_no) order
+type listCursor struct {
+	CreatedAt          time.Time
+	PartnerReferenceNo string
+}
+
+// encodeCursor encodes the position after order
+func encodeCursor(order *Order) string {
+	raw := order.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + order.PartnerReferenceNo
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor decodes a cursor produced by encodeCursor
+func decodeCursor(cursor string) (*listCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, partnerReferenceNo, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	return &listCursor{CreatedAt: t, PartnerReferenceNo: partnerReferenceNo}, nil
+}
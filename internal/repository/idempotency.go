@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyRecord is the stored outcome of a request made with an idempotency key
+type IdempotencyRecord struct {
+	Key          string
+	RequestHash  string // SHA-256 of method, path and canonical body of the original request
+	StatusCode   int
+	ResponseBody []byte
+	CreatedAt    time.Time
+}
+
+// IdempotencyRepository stores responses of idempotent requests for replay
+type IdempotencyRepository interface {
+	// GetIdempotency returns the record for key, returning ErrNotFound if missing
+	GetIdempotency(ctx context.Context, key string) (*IdempotencyRecord, error)
+	// SaveIdempotency stores a record, returning ErrDuplicate if the key is already used
+	SaveIdempotency(ctx context.Context, record *IdempotencyRecord) error
+	// DeleteExpiredIdempotency removes records created before createdBefore, freeing their keys
+	DeleteExpiredIdempotency(ctx context.Context, createdBefore time.Time) error
+}
@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/riyanathariq/dana-enterprise/internal/errs"
+)
+
+// MemoryOrderRepository is an in-memory OrderRepository, IdempotencyRepository and RefundRepository, intended for tests
+type MemoryOrderRepository struct {
+	mu          sync.RWMutex
+	orders      map[string]Order
+	idempotency map[string]IdempotencyRecord
+	refunds     map[string]Refund
+}
+
+// NewMemoryOrderRepository creates an empty in-memory order repository
+func NewMemoryOrderRepository() *MemoryOrderRepository {
+	return &MemoryOrderRepository{
+		orders:      make(map[string]Order),
+		idempotency: make(map[string]IdempotencyRecord),
+		refunds:     make(map[string]Refund),
+	}
+}
+
+// Ping always succeeds for the in-memory repository
+func (r *MemoryOrderRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Create records a new order
+func (r *MemoryOrderRepository) Create(ctx context.Context, order *Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.orders[order.PartnerReferenceNo]; exists {
+		return ErrDuplicate
+	}
+
+	now := time.Now()
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = now
+	}
+	order.UpdatedAt = now
+	r.orders[order.PartnerReferenceNo] = *order
+	return nil
+}
+
+// Get returns an order by partner reference number
+func (r *MemoryOrderRepository) Get(ctx context.Context, partnerReferenceNo string) (*Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	order, ok := r.orders[partnerReferenceNo]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &order, nil
+}
+
+// Update replaces a stored order
+func (r *MemoryOrderRepository) Update(ctx context.Context, order *Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.orders[order.PartnerReferenceNo]
+	if !ok {
+		return ErrNotFound
+	}
+	if !CanTransition(stored.Status, order.Status) {
+		return errs.ErrFinalStatus
+	}
+
+	order.CreatedAt = stored.CreatedAt
+	order.UpdatedAt = time.Now()
+	r.orders[order.PartnerReferenceNo] = *order
+	return nil
+}
+
+// List returns orders matching filter, newest first
+func (r *MemoryOrderRepository) List(ctx context.Context, filter OrderFilter) ([]*Order, string, error) {
+	cursor, err := decodeCursor(filter.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	r.mu.RLock()
+	matched := make([]*Order, 0)
+	for _, stored := range r.orders {
+		order := stored
+		if !matchesFilter(&order, filter) {
+			continue
+		}
+		if cursor != nil && !isAfterCursor(&order, cursor) {
+			continue
+		}
+		matched = append(matched, &order)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].PartnerReferenceNo > matched[j].PartnerReferenceNo
+	})
+
+	limit := filter.normalizedLimit()
+	if len(matched) <= limit {
+		return matched, "", nil
+	}
+	page := matched[:limit]
+	return page, encodeCursor(page[limit-1]), nil
+}
+
+// matchesFilter reports whether order satisfies every non-empty field of filter
+func matchesFilter(order *Order, filter OrderFilter) bool {
+	if filter.Status != "" && order.Status != filter.Status {
+		return false
+	}
+	if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, order.Status) {
+		return false
+	}
+	if filter.MerchantID != "" && order.MerchantID != filter.MerchantID {
+		return false
+	}
+	if filter.ClientID != "" && order.ClientID != filter.ClientID {
+		return false
+	}
+	if filter.SubMerchantID != "" && order.SubMerchantID != filter.SubMerchantID {
+		return false
+	}
+	if filter.ExternalStoreID != "" && order.ExternalStoreID != filter.ExternalStoreID {
+		return false
+	}
+	if filter.CreatedFrom != nil && order.CreatedAt.Before(*filter.CreatedFrom) {
+		return false
+	}
+	if filter.CreatedTo != nil && !order.CreatedAt.Before(*filter.CreatedTo) {
+		return false
+	}
+	if filter.MinAmount != nil && order.AmountMinor < *filter.MinAmount {
+		return false
+	}
+	if filter.MaxAmount != nil && order.AmountMinor > *filter.MaxAmount {
+		return false
+	}
+	return true
+}
+
+// isAfterCursor reports whether order sorts after the cursor position (newest first)
+func isAfterCursor(order *Order, cursor *listCursor) bool {
+	if order.CreatedAt.Equal(cursor.CreatedAt) {
+		return order.PartnerReferenceNo < cursor.PartnerReferenceNo
+	}
+	return order.CreatedAt.Before(cursor.CreatedAt)
+}
+
+// GetIdempotency returns the record for key
+func (r *MemoryOrderRepository) GetIdempotency(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, ok := r.idempotency[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &record, nil
+}
+
+// SaveIdempotency stores a record
+func (r *MemoryOrderRepository) SaveIdempotency(ctx context.Context, record *IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.idempotency[record.Key]; exists {
+		return ErrDuplicate
+	}
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+	r.idempotency[record.Key] = *record
+	return nil
+}
+
+// DeleteExpiredIdempotency removes records created before createdBefore
+func (r *MemoryOrderRepository) DeleteExpiredIdempotency(ctx context.Context, createdBefore time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, record := range r.idempotency {
+		if record.CreatedAt.Before(createdBefore) {
+			delete(r.idempotency, key)
+		}
+	}
+	return nil
+}
+
+// ReserveRefund records a pending refund if it fits into limitMinor, see RefundRepository
+func (r *MemoryOrderRepository) ReserveRefund(ctx context.Context, refund *Refund, limitMinor int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.refunds[refund.PartnerRefundNo]; exists {
+		return 0, ErrDuplicate
+	}
+
+	var refunded int64
+	for _, stored := range r.refunds {
+		if stored.PartnerReferenceNo != refund.PartnerReferenceNo {
+			continue
+		}
+		if stored.Status == RefundStatusPending || stored.Status == RefundStatusSuccess {
+			refunded += stored.AmountMinor
+		}
+	}
+	if refunded+refund.AmountMinor > limitMinor {
+		return refunded, ErrRefundLimitExceeded
+	}
+
+	now := time.Now()
+	if refund.CreatedAt.IsZero() {
+		refund.CreatedAt = now
+	}
+	refund.UpdatedAt = now
+	r.refunds[refund.PartnerRefundNo] = *refund
+	return refunded, nil
+}
+
+// GetRefund returns a refund by partner refund number
+func (r *MemoryOrderRepository) GetRefund(ctx context.Context, partnerRefundNo string) (*Refund, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	refund, ok := r.refunds[partnerRefundNo]
+	if !ok {
+		return nil, ErrRefundNotFound
+	}
+	return &refund, nil
+}
+
+// UpdateRefund replaces a stored refund
+func (r *MemoryOrderRepository) UpdateRefund(ctx context.Context, refund *Refund) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.refunds[refund.PartnerRefundNo]
+	if !ok {
+		return ErrRefundNotFound
+	}
+
+	refund.CreatedAt = stored.CreatedAt
+	refund.UpdatedAt = time.Now()
+	r.refunds[refund.PartnerRefundNo] = *refund
+	return nil
+}
+
+// DeleteRefund removes a refund
+func (r *MemoryOrderRepository) DeleteRefund(ctx context.Context, partnerRefundNo string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.refunds, partnerRefundNo)
+	return nil
+}
+
+// ListPendingRefunds returns up to limit pending refunds created before createdBefore, oldest first
+func (r *MemoryOrderRepository) ListPendingRefunds(ctx context.Context, createdBefore time.Time, limit int) ([]*Refund, error) {
+	r.mu.RLock()
+	refunds := make([]*Refund, 0)
+	for _, stored := range r.refunds {
+		refund := stored
+		if refund.Status == RefundStatusPending && refund.CreatedAt.Before(createdBefore) {
+			refunds = append(refunds, &refund)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(refunds, func(i, j int) bool {
+		if !refunds[i].CreatedAt.Equal(refunds[j].CreatedAt) {
+			return refunds[i].CreatedAt.Before(refunds[j].CreatedAt)
+		}
+		return refunds[i].PartnerRefundNo < refunds[j].PartnerRefundNo
+	})
+	if len(refunds) > limit {
+		refunds = refunds[:limit]
+	}
+	return refunds, nil
+}
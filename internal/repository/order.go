@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+var (
+	// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrNotFound is returned when no order exists for a partner reference number
+	ErrNotFound = errors.New("order not found")
+	// ErrDuplicate is returned when an order with the same partner reference number already exists
+	ErrDuplicate = errors.New("order already exists")
+)
+
+// Checkout types
+const (
+	CheckoutTypeHosted = "HOSTED"
+	CheckoutTypeCustom = "CUSTOM"
+)
+
+// Order statuses, mirroring DANA latestTransactionStatus
+const (
+	StatusInitiated = "INITIATED"
+	StatusPaying    = "PAYING"
+	StatusPending   = "PENDING"
+	StatusSuccess   = "SUCCESS"
+	StatusRefunded  = "REFUNDED"
+	StatusCancelled = "CANCELLED"
+	StatusFailed    = "FAILED"
+	StatusExpired   = "EXPIRED"
+)
+
+// terminalStatuses are order statuses no later DANA status replaces
+var terminalStatuses = []string{StatusSuccess, StatusRefunded, StatusCancelled, StatusFailed, StatusExpired}
+
+// IsTerminal reports whether status is final
+func IsTerminal(status string) bool {
+	return slices.Contains(terminalStatuses, status)
+}
+
+// CanTransition reports whether an order in status from may be updated to status to
+// Terminal statuses are never downgraded, the only way out of one is a paid order being refunded
+func CanTransition(from, to string) bool {
+	return from == to || !IsTerminal(from) || (from == StatusSuccess && to == StatusRefunded)
+}
+
+// Order represents an order recorded in the local ledger
+type Order struct {
+	PartnerReferenceNo string // Transaction identifier on partner system (natural key)
+	MerchantID         string
+	SubMerchantID      string
+	ExternalStoreID    string
+	AmountValue        string
+	AmountCurrency     string
+	AmountMinor        int64  // AmountValue in minor units, used by the amount filters
+	CheckoutType       string // HOSTED or CUSTOM
+	ReferenceNo        string // DANA referenceNo
+	WebRedirectURL     string
+	Status             string
+	ValidUpTo          string
+	ClientID           string // API client that created the order, empty when authentication is disabled
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// OrderRepository stores orders created through this service
+type OrderRepository interface {
+	// Create records a new order, returning ErrDuplicate if it already exists
+	Create(ctx context.Context, order *Order) error
+	// Get returns an order by partner reference number, returning ErrNotFound if missing
+	Get(ctx context.Context, partnerReferenceNo string) (*Order, error)
+	// Update replaces a stored order, returning ErrNotFound if missing and errs.ErrFinalStatus if the stored status
+	// cannot change to the status of order. The status check and the write are atomic
+	Update(ctx context.Context, order *Order) error
+	// List returns orders matching filter, newest first, and the cursor of the next page (empty on the last page)
+	List(ctx context.Context, filter OrderFilter) ([]*Order, string, error)
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/riyanathariq/dana-enterprise/internal/errs"
+)
+
+// testRepositories returns an empty repository of each implementation
+func testRepositories(t *testing.T) map[string]interface {
+	OrderRepository
+	RefundRepository
+} {
+	t.Helper()
+	sqlite, err := NewSQLiteOrderRepository(filepath.Join(t.TempDir(), "ledger.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteOrderRepository: %v", err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+
+	return map[string]interface {
+		OrderRepository
+		RefundRepository
+	}{
+		"memory": NewMemoryOrderRepository(),
+		"sqlite": sqlite,
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{StatusInitiated, StatusPaying, true},
+		{StatusPending, StatusSuccess, true},
+		{StatusPending, StatusExpired, true},
+		{StatusSuccess, StatusSuccess, true},
+		{StatusSuccess, StatusRefunded, true},
+		{StatusSuccess, StatusCancelled, false},
+		{StatusRefunded, StatusSuccess, false},
+		{StatusExpired, StatusCancelled, false},
+		{StatusCancelled, StatusPending, false},
+		{StatusFailed, StatusSuccess, false},
+	}
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateKeepsTerminalStatus(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			order := &Order{
+				PartnerReferenceNo: "ORDER-001",
+				MerchantID:         "216620000000000000000",
+				AmountValue:        "10000.00",
+				AmountCurrency:     "IDR",
+				AmountMinor:        1000000,
+				CheckoutType:       CheckoutTypeHosted,
+				Status:             StatusInitiated,
+			}
+			if err := repo.Create(ctx, order); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			for _, status := range []string{StatusSuccess, StatusRefunded} {
+				order.Status = status
+				if err := repo.Update(ctx, order); err != nil {
+					t.Fatalf("Update to %s: %v", status, err)
+				}
+			}
+
+			order.Status = StatusCancelled
+			if err := repo.Update(ctx, order); !errors.Is(err, errs.ErrFinalStatus) {
+				t.Fatalf("Update REFUNDED to CANCELLED: err = %v, want errs.ErrFinalStatus", err)
+			}
+			stored, err := repo.Get(ctx, order.PartnerReferenceNo)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if stored.Status != StatusRefunded {
+				t.Fatalf("status = %s, want %s", stored.Status, StatusRefunded)
+			}
+
+			missing := *order
+			missing.PartnerReferenceNo = "ORDER-404"
+			if err := repo.Update(ctx, &missing); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Update missing order: err = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestListFiltersByAmount(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			for i, amount := range []struct {
+				value string
+				minor int64
+			}{{"9999.99", 999999}, {"10000.00", 1000000}, {"10000.01", 1000001}, {"250000.00", 25000000}} {
+				err := repo.Create(ctx, &Order{
+					PartnerReferenceNo: "ORDER-" + amount.value,
+					MerchantID:         "216620000000000000000",
+					AmountValue:        amount.value,
+					AmountCurrency:     "IDR",
+					AmountMinor:        amount.minor,
+					CheckoutType:       CheckoutTypeHosted,
+					Status:             StatusInitiated,
+					CreatedAt:          time.Date(2025, 11, 5, 12, i, 0, 0, time.UTC),
+				})
+				if err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+
+			minAmount, maxAmount := int64(1000000), int64(1000001)
+			orders, _, err := repo.List(ctx, OrderFilter{MinAmount: &minAmount, MaxAmount: &maxAmount})
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			var refs []string
+			for _, order := range orders {
+				refs = append(refs, order.PartnerReferenceNo)
+			}
+			if len(refs) != 2 || refs[0] != "ORDER-10000.01" || refs[1] != "ORDER-10000.00" {
+				t.Fatalf("orders = %v, want [ORDER-10000.01 ORDER-10000.00]", refs)
+			}
+		})
+	}
+}
+
+func TestMigrationBackfillsAmountMinor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	// orders table as created before amount_minor existed
+	_, err = db.Exec(`CREATE TABLE orders (
+		partner_reference_no TEXT PRIMARY KEY,
+		merchant_id          TEXT NOT NULL,
+		sub_merchant_id      TEXT NOT NULL DEFAULT '',
+		external_store_id    TEXT NOT NULL DEFAULT '',
+		amount_value         TEXT NOT NULL,
+		amount_currency      TEXT NOT NULL,
+		checkout_type        TEXT NOT NULL,
+		reference_no         TEXT NOT NULL DEFAULT '',
+		web_redirect_url     TEXT NOT NULL DEFAULT '',
+		status               TEXT NOT NULL,
+		valid_up_to          TEXT NOT NULL DEFAULT '',
+		created_at           TIMESTAMP NOT NULL,
+		updated_at           TIMESTAMP NOT NULL
+	);
+	INSERT INTO orders (partner_reference_no, merchant_id, amount_value, amount_currency, checkout_type, status, created_at, updated_at)
+	VALUES ('ORDER-001', '216620000000000000000', '15000.50', 'IDR', 'HOSTED', 'SUCCESS', '2025-11-05 12:00:00', '2025-11-05 12:00:00')`)
+	if err != nil {
+		t.Fatalf("create legacy schema: %v", err)
+	}
+	db.Close()
+
+	repo, err := NewSQLiteOrderRepository(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteOrderRepository: %v", err)
+	}
+	defer repo.Close()
+
+	order, err := repo.Get(context.Background(), "ORDER-001")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if order.AmountMinor != 1500050 {
+		t.Fatalf("amount_minor = %d, want 1500050", order.AmountMinor)
+	}
+}
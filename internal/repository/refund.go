@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrRefundNotFound is returned when no refund exists for a partner refund number
+	ErrRefundNotFound = errors.New("refund not found")
+	// ErrRefundLimitExceeded is returned when a refund would take the refunds of an order past the order amount
+	ErrRefundLimitExceeded = errors.New("refund limit exceeded")
+)
+
+// Refund statuses
+const (
+	RefundStatusPending = "PENDING" // Sent to DANA, counts towards the refunded amount until it is resolved
+	RefundStatusSuccess = "SUCCESS"
+)
+
+// Refund represents a refund issued through this service
+type Refund struct {
+	PartnerRefundNo    string // Refund identifier on partner system (natural key)
+	PartnerReferenceNo string // Order the refund belongs to
+	MerchantID         string
+	RefundNo           string // DANA refund number
+	AmountValue        string
+	AmountCurrency     string
+	AmountMinor        int64 // AmountValue in minor units, summed to enforce the refundable amount
+	Reason             string
+	Status             string
+	RefundTime         string
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// RefundRepository stores refunds issued through this service
+type RefundRepository interface {
+	// ReserveRefund records a pending refund if the pending and successful refunds of its order, including this one,
+	// add up to at most limitMinor. The check and the insert are atomic. It returns the minor units already refunded
+	// or reserved for the order, ErrDuplicate if the partner refund number is used and ErrRefundLimitExceeded if the
+	// refund does not fit
+	ReserveRefund(ctx context.Context, refund *Refund, limitMinor int64) (int64, error)
+	// GetRefund returns a refund by partner refund number, returning ErrRefundNotFound if missing
+	GetRefund(ctx context.Context, partnerRefundNo string) (*Refund, error)
+	// UpdateRefund replaces a stored refund, returning ErrRefundNotFound if missing
+	UpdateRefund(ctx context.Context, refund *Refund) error
+	// DeleteRefund removes a refund, releasing its amount and partner refund number
+	DeleteRefund(ctx context.Context, partnerRefundNo string) error
+	// ListPendingRefunds returns up to limit pending refunds created before createdBefore, oldest first
+	ListPendingRefunds(ctx context.Context, createdBefore time.Time, limit int) ([]*Refund, error)
+}
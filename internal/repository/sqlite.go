@@ -0,0 +1,545 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/riyanathariq/dana-enterprise/internal/errs"
+	"github.com/riyanathariq/dana-enterprise/internal/money"
+)
+
+const ordersSchema = `
+CREATE TABLE IF NOT EXISTS orders (
+	partner_reference_no TEXT PRIMARY KEY,
+	merchant_id          TEXT NOT NULL,
+	sub_merchant_id      TEXT NOT NULL DEFAULT '',
+	external_store_id    TEXT NOT NULL DEFAULT '',
+	amount_value         TEXT NOT NULL,
+	amount_currency      TEXT NOT NULL,
+	amount_minor         INTEGER NOT NULL DEFAULT 0,
+	checkout_type        TEXT NOT NULL,
+	reference_no         TEXT NOT NULL DEFAULT '',
+	web_redirect_url     TEXT NOT NULL DEFAULT '',
+	status               TEXT NOT NULL,
+	valid_up_to          TEXT NOT NULL DEFAULT '',
+	client_id            TEXT NOT NULL DEFAULT '',
+	created_at           TIMESTAMP NOT NULL,
+	updated_at           TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_orders_status ON orders (status);
+CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders (created_at);
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	idempotency_key TEXT PRIMARY KEY,
+	request_hash    TEXT NOT NULL,
+	status_code     INTEGER NOT NULL,
+	response_body   BLOB NOT NULL,
+	created_at      TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys (created_at);
+CREATE TABLE IF NOT EXISTS refunds (
+	partner_refund_no    TEXT PRIMARY KEY,
+	partner_reference_no TEXT NOT NULL,
+	merchant_id          TEXT NOT NULL,
+	refund_no            TEXT NOT NULL DEFAULT '',
+	amount_value         TEXT NOT NULL,
+	amount_currency      TEXT NOT NULL,
+	amount_minor         INTEGER NOT NULL,
+	reason               TEXT NOT NULL DEFAULT '',
+	status               TEXT NOT NULL,
+	refund_time          TEXT NOT NULL DEFAULT '',
+	created_at           TIMESTAMP NOT NULL,
+	updated_at           TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_refunds_partner_reference_no ON refunds (partner_reference_no);
+CREATE INDEX IF NOT EXISTS idx_refunds_status ON refunds (status);
+`
+
+// orderMigrations add columns introduced after the orders table was first created
+// SQLite has no ADD COLUMN IF NOT EXISTS, so they run only when the column is missing
+// backfill, if set, fills the new column of existing rows
+var orderMigrations = []struct {
+	column   string
+	ddl      string
+	backfill func(db *sql.DB) error
+}{
+	{"client_id", `ALTER TABLE orders ADD COLUMN client_id TEXT NOT NULL DEFAULT ''`, nil},
+	{"amount_minor", `ALTER TABLE orders ADD COLUMN amount_minor INTEGER NOT NULL DEFAULT 0`, backfillAmountMinor},
+}
+
+const orderColumns = `partner_reference_no, merchant_id, sub_merchant_id, external_store_id,
+	amount_value, amount_currency, amount_minor, checkout_type, reference_no, web_redirect_url,
+	status, valid_up_to, client_id, created_at, updated_at`
+
+const refundColumns = `partner_refund_no, partner_reference_no, merchant_id, refund_no,
+	amount_value, amount_currency, amount_minor, reason, status, refund_time, created_at, updated_at`
+
+// transitionGuard restricts an UPDATE of orders to rows whose status may change to the status bound to both
+// placeholders, mirroring CanTransition
+var transitionGuard = `(status = ? OR status NOT IN ('` + strings.Join(terminalStatuses, "', '") + `')
+	OR (status = '` + StatusSuccess + `' AND ? = '` + StatusRefunded + `'))`
+
+// SQLiteOrderRepository is an OrderRepository, IdempotencyRepository and RefundRepository backed by an embedded SQLite database
+type SQLiteOrderRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteOrderRepository opens (or creates) the SQLite database at path and migrates the schema
+func NewSQLiteOrderRepository(path string) (*SQLiteOrderRepository, error) {
+	// Immediate transactions take the write lock up front, so read-then-write transactions such as
+	// ReserveRefund are serialized across processes sharing the database
+	db, err := sql.Open("sqlite3", path+"?_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	// SQLite allows a single writer; serialize access through one connection
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(ordersSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate sqlite database: %w", err)
+	}
+	if err := migrateOrderColumns(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate sqlite database: %w", err)
+	}
+
+	return &SQLiteOrderRepository{db: db}, nil
+}
+
+// Close closes the underlying database
+func (r *SQLiteOrderRepository) Close() error {
+	return r.db.Close()
+}
+
+// Ping checks that the database can still be reached
+func (r *SQLiteOrderRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Create records a new order
+func (r *SQLiteOrderRepository) Create(ctx context.Context, order *Order) error {
+	now := time.Now().UTC()
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = now
+	}
+	order.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO orders (`+orderColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		order.PartnerReferenceNo, order.MerchantID, order.SubMerchantID, order.ExternalStoreID,
+		order.AmountValue, order.AmountCurrency, order.AmountMinor, order.CheckoutType, order.ReferenceNo, order.WebRedirectURL,
+		order.Status, order.ValidUpTo, order.ClientID, order.CreatedAt, order.UpdatedAt,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("failed to insert order: %w", err)
+	}
+	return nil
+}
+
+// Get returns an order by partner reference number
+func (r *SQLiteOrderRepository) Get(ctx context.Context, partnerReferenceNo string) (*Order, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+orderColumns+` FROM orders WHERE partner_reference_no = ?`, partnerReferenceNo)
+
+	order, err := scanOrder(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	return order, nil
+}
+
+// Update replaces a stored order
+func (r *SQLiteOrderRepository) Update(ctx context.Context, order *Order) error {
+	order.UpdatedAt = time.Now().UTC()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE orders SET
+		merchant_id = ?, sub_merchant_id = ?, external_store_id = ?,
+		amount_value = ?, amount_currency = ?, amount_minor = ?, checkout_type = ?, reference_no = ?, web_redirect_url = ?,
+		status = ?, valid_up_to = ?, updated_at = ?
+		WHERE partner_reference_no = ? AND `+transitionGuard,
+		order.MerchantID, order.SubMerchantID, order.ExternalStoreID,
+		order.AmountValue, order.AmountCurrency, order.AmountMinor, order.CheckoutType, order.ReferenceNo, order.WebRedirectURL,
+		order.Status, order.ValidUpTo, order.UpdatedAt,
+		order.PartnerReferenceNo, order.Status, order.Status,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update order: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update order: %w", err)
+	}
+	if affected == 0 {
+		// Either the order is missing or its status is final
+		if _, err := r.Get(ctx, order.PartnerReferenceNo); err != nil {
+			return err
+		}
+		return errs.ErrFinalStatus
+	}
+	return nil
+}
+
+// List returns orders matching filter, newest first
+func (r *SQLiteOrderRepository) List(ctx context.Context, filter OrderFilter) ([]*Order, string, error) {
+	cursor, err := decodeCursor(filter.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var conditions []string
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		conditions = append(conditions, condition)
+		args = append(args, arg)
+	}
+
+	if filter.Status != "" {
+		addCondition("status = ?", filter.Status)
+	}
+	if len(filter.Statuses) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(filter.Statuses)), ",")
+		conditions = append(conditions, "status IN ("+placeholders+")")
+		for _, status := range filter.Statuses {
+			args = append(args, status)
+		}
+	}
+	if filter.MerchantID != "" {
+		addCondition("merchant_id = ?", filter.MerchantID)
+	}
+	if filter.SubMerchantID != "" {
+		addCondition("sub_merchant_id = ?", filter.SubMerchantID)
+	}
+	if filter.ClientID != "" {
+		addCondition("client_id = ?", filter.ClientID)
+	}
+	if filter.ExternalStoreID != "" {
+		addCondition("external_store_id = ?", filter.ExternalStoreID)
+	}
+	if filter.CreatedFrom != nil {
+		addCondition("created_at >= ?", filter.CreatedFrom.UTC())
+	}
+	if filter.CreatedTo != nil {
+		addCondition("created_at < ?", filter.CreatedTo.UTC())
+	}
+	if filter.MinAmount != nil {
+		addCondition("amount_minor >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		addCondition("amount_minor <= ?", *filter.MaxAmount)
+	}
+	if cursor != nil {
+		conditions = append(conditions, "(created_at < ? OR (created_at = ? AND partner_reference_no < ?))")
+		args = append(args, cursor.CreatedAt.UTC(), cursor.CreatedAt.UTC(), cursor.PartnerReferenceNo)
+	}
+
+	query := `SELECT ` + orderColumns + ` FROM orders`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	// Fetch one extra row to know whether there is a next page
+	limit := filter.normalizedLimit()
+	query += ` ORDER BY created_at DESC, partner_reference_no DESC LIMIT ?`
+	args = append(args, limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]*Order, 0, limit)
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	if len(orders) <= limit {
+		return orders, "", nil
+	}
+	page := orders[:limit]
+	return page, encodeCursor(page[limit-1]), nil
+}
+
+// GetIdempotency returns the record for key
+func (r *SQLiteOrderRepository) GetIdempotency(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	var record IdempotencyRecord
+	err := r.db.QueryRowContext(ctx, `SELECT idempotency_key, request_hash, status_code, response_body, created_at
+		FROM idempotency_keys WHERE idempotency_key = ?`, key).
+		Scan(&record.Key, &record.RequestHash, &record.StatusCode, &record.ResponseBody, &record.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	return &record, nil
+}
+
+// SaveIdempotency stores a record
+func (r *SQLiteOrderRepository) SaveIdempotency(ctx context.Context, record *IdempotencyRecord) error {
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now().UTC()
+	}
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO idempotency_keys
+		(idempotency_key, request_hash, status_code, response_body, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		record.Key, record.RequestHash, record.StatusCode, record.ResponseBody, record.CreatedAt,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("failed to save idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotency removes records created before createdBefore
+func (r *SQLiteOrderRepository) DeleteExpiredIdempotency(ctx context.Context, createdBefore time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE created_at < ?`, createdBefore.UTC()); err != nil {
+		return fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+	return nil
+}
+
+// ReserveRefund records a pending refund if it fits into limitMinor, see RefundRepository
+func (r *SQLiteOrderRepository) ReserveRefund(ctx context.Context, refund *Refund, limitMinor int64) (int64, error) {
+	now := time.Now().UTC()
+	if refund.CreatedAt.IsZero() {
+		refund.CreatedAt = now
+	}
+	refund.UpdatedAt = now
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve refund: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM refunds WHERE partner_refund_no = ?`, refund.PartnerRefundNo).Scan(&exists)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve refund: %w", err)
+	}
+	if exists > 0 {
+		return 0, ErrDuplicate
+	}
+
+	// Pending refunds count towards the total so concurrent refunds cannot exceed the order amount
+	var refunded int64
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(amount_minor), 0) FROM refunds
+		WHERE partner_reference_no = ? AND status IN (?, ?)`,
+		refund.PartnerReferenceNo, RefundStatusPending, RefundStatusSuccess,
+	).Scan(&refunded)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve refund: %w", err)
+	}
+	if refunded+refund.AmountMinor > limitMinor {
+		return refunded, ErrRefundLimitExceeded
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO refunds (`+refundColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		refund.PartnerRefundNo, refund.PartnerReferenceNo, refund.MerchantID, refund.RefundNo,
+		refund.AmountValue, refund.AmountCurrency, refund.AmountMinor, refund.Reason, refund.Status, refund.RefundTime,
+		refund.CreatedAt, refund.UpdatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve refund: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to reserve refund: %w", err)
+	}
+	return refunded, nil
+}
+
+// GetRefund returns a refund by partner refund number
+func (r *SQLiteOrderRepository) GetRefund(ctx context.Context, partnerRefundNo string) (*Refund, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+refundColumns+` FROM refunds WHERE partner_refund_no = ?`, partnerRefundNo)
+
+	refund, err := scanRefund(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRefundNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refund: %w", err)
+	}
+	return refund, nil
+}
+
+// UpdateRefund replaces a stored refund
+func (r *SQLiteOrderRepository) UpdateRefund(ctx context.Context, refund *Refund) error {
+	refund.UpdatedAt = time.Now().UTC()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE refunds SET
+		refund_no = ?, reason = ?, status = ?, refund_time = ?, updated_at = ?
+		WHERE partner_refund_no = ?`,
+		refund.RefundNo, refund.Reason, refund.Status, refund.RefundTime, refund.UpdatedAt,
+		refund.PartnerRefundNo,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update refund: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update refund: %w", err)
+	}
+	if affected == 0 {
+		return ErrRefundNotFound
+	}
+	return nil
+}
+
+// DeleteRefund removes a refund
+func (r *SQLiteOrderRepository) DeleteRefund(ctx context.Context, partnerRefundNo string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM refunds WHERE partner_refund_no = ?`, partnerRefundNo); err != nil {
+		return fmt.Errorf("failed to delete refund: %w", err)
+	}
+	return nil
+}
+
+// ListPendingRefunds returns up to limit pending refunds created before createdBefore, oldest first
+func (r *SQLiteOrderRepository) ListPendingRefunds(ctx context.Context, createdBefore time.Time, limit int) ([]*Refund, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+refundColumns+` FROM refunds
+		WHERE status = ? AND created_at < ? ORDER BY created_at, partner_refund_no LIMIT ?`,
+		RefundStatusPending, createdBefore.UTC(), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending refunds: %w", err)
+	}
+	defer rows.Close()
+
+	var refunds []*Refund
+	for rows.Next() {
+		refund, err := scanRefund(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan refund: %w", err)
+		}
+		refunds = append(refunds, refund)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list pending refunds: %w", err)
+	}
+	return refunds, nil
+}
+
+// migrateOrderColumns adds the orderMigrations columns missing from an existing orders table
+func migrateOrderColumns(db *sql.DB) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('orders')`)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, migration := range orderMigrations {
+		if existing[migration.column] {
+			continue
+		}
+		if _, err := db.Exec(migration.ddl); err != nil {
+			return fmt.Errorf("failed to add column %s: %w", migration.column, err)
+		}
+		if migration.backfill != nil {
+			if err := migration.backfill(db); err != nil {
+				return fmt.Errorf("failed to backfill column %s: %w", migration.column, err)
+			}
+		}
+	}
+	return nil
+}
+
+// backfillAmountMinor fills amount_minor of orders recorded before the column existed
+func backfillAmountMinor(db *sql.DB) error {
+	rows, err := db.Query(`SELECT partner_reference_no, amount_value, amount_currency FROM orders`)
+	if err != nil {
+		return err
+	}
+	amounts := make(map[string]int64)
+	for rows.Next() {
+		var partnerReferenceNo, value, currency string
+		if err := rows.Scan(&partnerReferenceNo, &value, &currency); err != nil {
+			rows.Close()
+			return err
+		}
+		amount, err := money.Parse(value, currency)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("order %s: %w", partnerReferenceNo, err)
+		}
+		amounts[partnerReferenceNo] = amount.Minor()
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for partnerReferenceNo, minor := range amounts {
+		if _, err := db.Exec(`UPDATE orders SET amount_minor = ? WHERE partner_reference_no = ?`, minor, partnerReferenceNo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanOrder scans a single orders row selected with orderColumns
+func scanOrder(row rowScanner) (*Order, error) {
+	var order Order
+	err := row.Scan(
+		&order.PartnerReferenceNo, &order.MerchantID, &order.SubMerchantID, &order.ExternalStoreID,
+		&order.AmountValue, &order.AmountCurrency, &order.AmountMinor, &order.CheckoutType, &order.ReferenceNo, &order.WebRedirectURL,
+		&order.Status, &order.ValidUpTo, &order.ClientID, &order.CreatedAt, &order.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// scanRefund scans a single refunds row selected with refundColumns
+func scanRefund(row rowScanner) (*Refund, error) {
+	var refund Refund
+	err := row.Scan(
+		&refund.PartnerRefundNo, &refund.PartnerReferenceNo, &refund.MerchantID, &refund.RefundNo,
+		&refund.AmountValue, &refund.AmountCurrency, &refund.AmountMinor, &refund.Reason, &refund.Status, &refund.RefundTime,
+		&refund.CreatedAt, &refund.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
@@ -1,46 +1,88 @@
 package route
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/riyanathariq/dana-enterprise/internal/auth"
+	"github.com/riyanathariq/dana-enterprise/internal/config"
 	"github.com/riyanathariq/dana-enterprise/internal/handler"
+	"github.com/riyanathariq/dana-enterprise/internal/metrics"
+	"github.com/riyanathariq/dana-enterprise/internal/middleware"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
 )
 
-func SetupRoutes() *gin.Engine {
+func SetupRoutes(danaHandler *handler.DanaHandler, healthHandler *handler.HealthHandler, authenticator *auth.Authenticator, limiter *middleware.RateLimiter, idempotencyRepository repository.IdempotencyRepository, idempotencyTTL time.Duration) *gin.Engine {
 	// Use gin.New() instead of gin.Default() to avoid duplicate middleware warning
 	r := gin.New()
 
 	// Add middleware manually
-	r.Use(gin.Logger())
+	r.Use(middleware.RequestID())
+	r.Use(middleware.RequestLogger())
 	r.Use(gin.Recovery())
 
-	// Health check
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status":  "ok",
-			"message": "Dana Enterprise API is running",
-		})
-	})
+	// Liveness and readiness probes
+	r.GET("/live", healthHandler.Live)
+	r.GET("/ready", healthHandler.Ready)
+
+	// Prometheus metrics
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// API routes
+	// Client routes require an API key once API clients are configured; DANA webhooks are signed by DANA instead
+	// Rate limits run after authentication, so they apply per API client
+	authenticate := middleware.Authenticate(authenticator)
+	scope := func(scope string) gin.HandlerFunc {
+		return middleware.RequireScope(authenticator, scope)
+	}
+
 	api := r.Group("/api/v1")
 	{
-		danaHandler := handler.NewDanaHandler()
-
 		// Merchant routes
-		merchant := api.Group("/merchant")
+		merchant := api.Group("/merchant", authenticate, limiter.Limit(config.RateLimitMerchant), scope(auth.ScopeMerchantRead))
 		{
 			merchant.GET("/info", danaHandler.GetMerchantInfo)
 			merchant.GET("/info/:merchant_id", danaHandler.GetMerchantInfo)
 		}
 
 		// Order routes
-		order := api.Group("/order")
+		order := api.Group("/order", authenticate)
 		{
-			order.POST("", danaHandler.CreateOrder)                      // Auto-detect: hosted or custom
-			order.POST("/custom", danaHandler.CreateOrderCustomCheckout) // Explicit custom checkout
+			idempotent := middleware.Idempotency(idempotencyRepository, idempotencyTTL)
+			createLimit := limiter.Limit(config.RateLimitOrderCreate)
+			orderLimit := limiter.Limit(config.RateLimitOrder)
+			order.POST("", createLimit, scope(auth.ScopeOrdersWrite), idempotent, danaHandler.CreateOrder)                      // Auto-detect: hosted or custom
+			order.POST("/custom", createLimit, scope(auth.ScopeOrdersWrite), idempotent, danaHandler.CreateOrderCustomCheckout) // Explicit custom checkout
 			// Specific routes must come before parameterized routes
-			order.GET("/payment/method", danaHandler.GetPaymentMethod)
-			order.GET("/:partner_reference_no", danaHandler.GetOrder)
+			order.GET("/payment/method", orderLimit, scope(auth.ScopeOrdersRead), danaHandler.GetPaymentMethod)
+			order.GET("/:partner_reference_no", orderLimit, scope(auth.ScopeOrdersRead), danaHandler.GetOrder)
+			order.POST("/:partner_reference_no/cancel", orderLimit, scope(auth.ScopeOrdersWrite), danaHandler.CancelOrder)
+			order.POST("/:partner_reference_no/refunds", orderLimit, scope(auth.ScopeRefundsWrite), danaHandler.RefundOrder)
+		}
+
+		// Catalogue of pay options accepted in pay_option_details, served without calling DANA
+		payment := api.Group("/payment", authenticate, limiter.Limit(config.RateLimitOrder), scope(auth.ScopeOrdersRead))
+		{
+			payment.GET("/options", danaHandler.GetPaymentOptions)
+		}
+
+		// Order listing over the local ledger
+		orders := api.Group("/orders", authenticate, limiter.Limit(config.RateLimitOrders), scope(auth.ScopeOrdersRead))
+		{
+			orders.GET("", danaHandler.ListOrders)
+		}
+
+		// Refund routes
+		refunds := api.Group("/refunds", authenticate, limiter.Limit(config.RateLimitRefunds), scope(auth.ScopeRefundsRead))
+		{
+			refunds.GET("/:partner_refund_no", danaHandler.GetRefund)
+		}
+
+		// Webhook routes (called by DANA, authenticated by X-SIGNATURE)
+		webhook := api.Group("/webhook")
+		{
+			webhook.POST("/dana/finish-notify", danaHandler.FinishNotify)
+			webhook.POST("/dana/finish-notify/:merchant_id", danaHandler.FinishNotify)
 		}
 	}
 
@@ -0,0 +1,311 @@
+// Package danatest provides an in-process fake DANA gateway for offline integration tests.
+//
+// The gateway implements the endpoints used by this service (create order, query payment,
+// consult pay, cancel, refund and merchant resource query), verifies the X-SIGNATURE of
+// incoming requests with a generated merchant key pair and keeps orders in memory.
+// Responses can be scripted per operation to simulate DANA error codes.
+//
+// Usage:
+//
+//	gw := danatest.NewGateway()
+//	defer gw.Close()
+//	gw.Setenv(t.Setenv) // points DANA_HOST and credentials at the fake gateway
+//	cfg, _ := config.Load(nil)
+//	gw.Enqueue(danatest.OpCreateOrder, danatest.ScriptedResponse{StatusCode: 409, ResponseCode: "4095401"})
+//
+// Call Setenv before config.Load so the configuration points at the gateway.
+package danatest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	danaSDK "github.com/riyanathariq/dana-enterprise/internal/sdk/dana"
+)
+
+// Operation identifies a DANA API operation served by the gateway
+type Operation string
+
+// Supported operations
+const (
+	OpCreateOrder           Operation = "CREATE_ORDER"
+	OpQueryPayment          Operation = "QUERY_PAYMENT"
+	OpConsultPay            Operation = "CONSULT_PAY"
+	OpCancelOrder           Operation = "CANCEL_ORDER"
+	OpRefundOrder           Operation = "REFUND_ORDER"
+	OpQueryMerchantResource Operation = "QUERY_MERCHANT_RESOURCE"
+)
+
+// Endpoint paths served by the gateway
+const (
+	PathCreateOrder           = "/payment-gateway/v1.0/debit/payment-host-to-host.htm"
+	PathQueryPayment          = "/payment-gateway/v1.0/debit/status.htm"
+	PathConsultPay            = "/v1.0/payment-gateway/consult-pay.htm"
+	PathCancelOrder           = "/payment-gateway/v1.0/debit/cancel.htm"
+	PathRefundOrder           = "/payment-gateway/v1.0/debit/refund.htm"
+	PathQueryMerchantResource = "/dana/merchant/queryMerchantResource.htm"
+)
+
+// Test credentials exported to the environment by Setenv
+const (
+	TestClientID     = "TEST-CLIENT-ID"
+	TestClientSecret = "TEST-CLIENT-SECRET"
+	TestMerchantID   = "TEST-MERCHANT-ID"
+)
+
+// ScriptedResponse overrides the next response of an operation
+type ScriptedResponse struct {
+	StatusCode      int                    // HTTP status, defaults to 200
+	ResponseCode    string                 // SNAP responseCode
+	ResponseMessage string                 // SNAP responseMessage
+	Fields          map[string]interface{} // Additional top-level response fields
+}
+
+// RecordedRequest is a request received by the gateway
+type RecordedRequest struct {
+	Operation Operation
+	Header    http.Header
+	Body      []byte
+}
+
+// Gateway is a fake DANA gateway backed by httptest.Server
+type Gateway struct {
+	server *httptest.Server
+
+	merchantKey *rsa.PrivateKey // Signs our requests, gateway verifies with its public part
+	danaKey     *rsa.PrivateKey // Signs webhooks sent by the gateway, we verify with DANA_PUBLIC_KEY
+
+	mu       sync.Mutex
+	scripts  map[Operation][]ScriptedResponse
+	requests []RecordedRequest
+	orders   map[string]*fakeOrder
+	refunds  map[string]*fakeRefund
+	balances map[string]string
+	sequence int
+}
+
+// NewGateway starts a fake DANA gateway with freshly generated key pairs
+func NewGateway() *Gateway {
+	g := &Gateway{
+		merchantKey: mustGenerateKey(),
+		danaKey:     mustGenerateKey(),
+		scripts:     make(map[Operation][]ScriptedResponse),
+		orders:      make(map[string]*fakeOrder),
+		refunds:     make(map[string]*fakeRefund),
+		balances: map[string]string{
+			"MERCHANT_DEPOSIT_BALANCE":   "1000000.00",
+			"MERCHANT_AVAILABLE_BALANCE": "750000.00",
+			"MERCHANT_TOTAL_BALANCE":     "1750000.00",
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathCreateOrder, g.snapHandler(OpCreateOrder, "54", g.createOrder))
+	mux.HandleFunc(PathQueryPayment, g.snapHandler(OpQueryPayment, "55", g.queryPayment))
+	mux.HandleFunc(PathConsultPay, g.snapHandler(OpConsultPay, "00", g.consultPay))
+	mux.HandleFunc(PathCancelOrder, g.snapHandler(OpCancelOrder, "57", g.cancelOrder))
+	mux.HandleFunc(PathRefundOrder, g.snapHandler(OpRefundOrder, "58", g.refundOrder))
+	mux.HandleFunc(PathQueryMerchantResource, g.queryMerchantResource)
+	g.server = httptest.NewServer(mux)
+
+	return g
+}
+
+// mustGenerateKey generates a 2048-bit RSA key, panicking on failure
+func mustGenerateKey() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(fmt.Sprintf("danatest: failed to generate RSA key: %v", err))
+	}
+	return key
+}
+
+// Close shuts down the gateway
+func (g *Gateway) Close() {
+	g.server.Close()
+}
+
+// URL returns the base URL of the gateway
+func (g *Gateway) URL() string {
+	return g.server.URL
+}
+
+// MerchantPrivateKeyPEM returns the private key our service signs requests with (DANA_PRIVATE_KEY)
+func (g *Gateway) MerchantPrivateKeyPEM() string {
+	der, _ := x509.MarshalPKCS8PrivateKey(g.merchantKey)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+// DanaPublicKeyPEM returns the public key webhooks are verified with (DANA_PUBLIC_KEY)
+func (g *Gateway) DanaPublicKeyPEM() string {
+	der, _ := x509.MarshalPKIXPublicKey(&g.danaKey.PublicKey)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// Env returns DANA_* environment variables pointing at the gateway
+func (g *Gateway) Env() map[string]string {
+	u, _ := url.Parse(g.server.URL)
+	return map[string]string{
+		"DANA_ENV":           "sandbox",
+		"DANA_HOST":          u.Host,
+		"DANA_SCHEME":        u.Scheme,
+		"DANA_CLIENT_ID":     TestClientID,
+		"DANA_CLIENT_SECRET": TestClientSecret,
+		"DANA_MERCHANT_ID":   TestMerchantID,
+		"DANA_PRIVATE_KEY":   g.MerchantPrivateKeyPEM(),
+		"DANA_PUBLIC_KEY":    g.DanaPublicKeyPEM(),
+	}
+}
+
+// Setenv exports Env through setenv, typically testing.T.Setenv
+func (g *Gateway) Setenv(setenv func(key, value string)) {
+	for key, value := range g.Env() {
+		setenv(key, value)
+	}
+}
+
+// Enqueue scripts the next response of op; scripted responses are consumed in FIFO order
+func (g *Gateway) Enqueue(op Operation, response ScriptedResponse) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.scripts[op] = append(g.scripts[op], response)
+}
+
+// Requests returns the requests received for op
+func (g *Gateway) Requests(op Operation) []RecordedRequest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var requests []RecordedRequest
+	for _, r := range g.requests {
+		if r.Operation == op {
+			requests = append(requests, r)
+		}
+	}
+	return requests
+}
+
+// SetBalance sets the value returned for a merchant resource type (e.g. MERCHANT_AVAILABLE_BALANCE)
+func (g *Gateway) SetBalance(resourceType, amount string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.balances[resourceType] = amount
+}
+
+// SetOrderStatus sets the latestTransactionStatus of an order (e.g. "00" to mark it paid)
+func (g *Gateway) SetOrderStatus(partnerReferenceNo, status string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	order, ok := g.orders[partnerReferenceNo]
+	if !ok {
+		return fmt.Errorf("danatest: order %s not found", partnerReferenceNo)
+	}
+	order.Status = status
+	return nil
+}
+
+// SignWebhook signs a webhook request the way DANA does, returning X-TIMESTAMP and X-SIGNATURE
+func (g *Gateway) SignWebhook(method, path string, body []byte) (string, string, error) {
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, body); err != nil {
+		return "", "", err
+	}
+
+	signer, err := danaSDK.NewSigner(g.danaPrivateKeyPEM())
+	if err != nil {
+		return "", "", err
+	}
+	timestamp := jakartaNow()
+	signature, err := signer.Sign(method, path, compacted.Bytes(), timestamp)
+	if err != nil {
+		return "", "", err
+	}
+	return timestamp, signature, nil
+}
+
+// NewFinishNotifyRequest builds a signed finish-notify request for an order, to be sent to our webhook
+func (g *Gateway) NewFinishNotifyRequest(targetURL, partnerReferenceNo, status string) (*http.Request, error) {
+	g.mu.Lock()
+	order, ok := g.orders[partnerReferenceNo]
+	var payload map[string]interface{}
+	if ok {
+		order.Status = status
+		payload = order.notifyPayload()
+	}
+	g.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("danatest: order %s not found", partnerReferenceNo)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, targetURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	timestamp, signature, err := g.SignWebhook(http.MethodPost, req.URL.Path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-TIMESTAMP", timestamp)
+	req.Header.Set("X-SIGNATURE", signature)
+	return req, nil
+}
+
+// danaPrivateKeyPEM returns the private key the gateway signs webhooks with
+func (g *Gateway) danaPrivateKeyPEM() string {
+	der, _ := x509.MarshalPKCS8PrivateKey(g.danaKey)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+// record stores a received request
+func (g *Gateway) record(op Operation, r *http.Request, body []byte) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.requests = append(g.requests, RecordedRequest{
+		Operation: op,
+		Header:    r.Header.Clone(),
+		Body:      body,
+	})
+}
+
+// nextScript pops the next scripted response for op
+func (g *Gateway) nextScript(op Operation) (ScriptedResponse, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	queue := g.scripts[op]
+	if len(queue) == 0 {
+		return ScriptedResponse{}, false
+	}
+	g.scripts[op] = queue[1:]
+	return queue[0], true
+}
+
+// readBody reads and returns the request body
+func readBody(r *http.Request) ([]byte, error) {
+	defer r.Body.Close()
+	return io.ReadAll(r.Body)
+}
+
+// writeJSON writes v as JSON with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
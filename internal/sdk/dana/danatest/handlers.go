@@ -0,0 +1,562 @@
+package danatest
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	danaSDK "github.com/riyanathariq/dana-enterprise/internal/sdk/dana"
+)
+
+// Transaction status codes returned in latestTransactionStatus
+const (
+	StatusSuccess   = "00"
+	StatusInitiated = "01"
+	StatusPaying    = "02"
+	StatusPending   = "03"
+	StatusRefunded  = "04"
+	StatusCancelled = "05"
+	StatusFailed    = "06"
+)
+
+// money is a SNAP amount object
+type money struct {
+	Value    string `json:"value"`
+	Currency string `json:"currency"`
+}
+
+// fakeOrder is an order created on the gateway
+type fakeOrder struct {
+	PartnerReferenceNo string
+	ReferenceNo        string
+	MerchantID         string
+	SubMerchantID      string
+	ExternalStoreID    string
+	Amount             money
+	Refunded           int64
+	Status             string
+	CreatedTime        time.Time
+}
+
+// notifyPayload builds the finish-notify payload DANA sends for the order
+func (o *fakeOrder) notifyPayload() map[string]interface{} {
+	payload := map[string]interface{}{
+		"originalPartnerReferenceNo": o.PartnerReferenceNo,
+		"originalReferenceNo":        o.ReferenceNo,
+		"merchantId":                 o.MerchantID,
+		"amount":                     o.Amount,
+		"latestTransactionStatus":    o.Status,
+		"transactionStatusDesc":      statusDesc(o.Status),
+		"createdTime":                jakartaTime(o.CreatedTime),
+		"finishedTime":               jakartaNow(),
+	}
+	if o.SubMerchantID != "" {
+		payload["subMerchantId"] = o.SubMerchantID
+	}
+	if o.ExternalStoreID != "" {
+		payload["externalStoreId"] = o.ExternalStoreID
+	}
+	return payload
+}
+
+// fakeRefund is a refund created on the gateway
+type fakeRefund struct {
+	PartnerRefundNo string
+	RefundNo        string
+	Amount          money
+}
+
+// apiError is a non-successful SNAP response produced by an operation handler
+type apiError struct {
+	status  int
+	caseNo  string
+	message string
+}
+
+// snapError builds an apiError, the response code is completed with the service code
+func snapError(status int, caseNo, message string) *apiError {
+	return &apiError{status: status, caseNo: caseNo, message: message}
+}
+
+// operationFunc handles a verified SNAP request and returns the response fields
+type operationFunc func(body map[string]interface{}) (map[string]interface{}, *apiError)
+
+// snapHandler wraps an operation with recording, signature verification and response scripting
+// SNAP response codes are "<HTTP status><service code><case code>", e.g. 2005400 for create order
+func (g *Gateway) snapHandler(op Operation, serviceCode string, handle operationFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := readBody(r)
+		if err != nil {
+			writeSNAPError(w, serviceCode, snapError(http.StatusBadRequest, "00", "Bad Request"))
+			return
+		}
+		g.record(op, r, body)
+
+		if r.Method != http.MethodPost {
+			writeSNAPError(w, serviceCode, snapError(http.StatusMethodNotAllowed, "00", "Method Not Allowed"))
+			return
+		}
+
+		if err := g.verifySignature(r.Method, r.URL.Path, body, r.Header.Get("X-TIMESTAMP"), r.Header.Get("X-SIGNATURE")); err != nil {
+			writeSNAPError(w, serviceCode, snapError(http.StatusUnauthorized, "00", "Unauthorized. "+err.Error()))
+			return
+		}
+		if r.Header.Get("X-PARTNER-ID") == "" || r.Header.Get("X-EXTERNAL-ID") == "" || r.Header.Get("CHANNEL-ID") == "" {
+			writeSNAPError(w, serviceCode, snapError(http.StatusBadRequest, "02", "Invalid Mandatory Field X-PARTNER-ID/X-EXTERNAL-ID/CHANNEL-ID"))
+			return
+		}
+
+		if script, ok := g.nextScript(op); ok {
+			writeScripted(w, serviceCode, script)
+			return
+		}
+
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			writeSNAPError(w, serviceCode, snapError(http.StatusBadRequest, "01", "Invalid Field Format"))
+			return
+		}
+
+		fields, apiErr := handle(payload)
+		if apiErr != nil {
+			writeSNAPError(w, serviceCode, apiErr)
+			return
+		}
+
+		fields["responseCode"] = "200" + serviceCode + "00"
+		fields["responseMessage"] = "Successful"
+		writeJSON(w, http.StatusOK, fields)
+	}
+}
+
+// verifySignature verifies a SNAP X-SIGNATURE against the merchant public key
+func (g *Gateway) verifySignature(method, path string, body []byte, timestamp, signature string) error {
+	if timestamp == "" || signature == "" {
+		return fmt.Errorf("missing X-TIMESTAMP or X-SIGNATURE")
+	}
+
+	var compacted bytes.Buffer
+	if len(body) > 0 {
+		if err := json.Compact(&compacted, body); err != nil {
+			return fmt.Errorf("body is not valid JSON")
+		}
+	}
+
+	return g.verify([]byte(danaSDK.StringToSign(method, path, compacted.Bytes(), timestamp)), signature)
+}
+
+// verify checks a base64 SHA256withRSA signature of data against the merchant public key
+func (g *Gateway) verify(data []byte, signature string) error {
+	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("signature is not valid base64")
+	}
+	hashed := sha256.Sum256(data)
+	if err := rsa.VerifyPKCS1v15(&g.merchantKey.PublicKey, crypto.SHA256, hashed[:], signatureBytes); err != nil {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// writeSNAPError writes a SNAP error response
+func writeSNAPError(w http.ResponseWriter, serviceCode string, apiErr *apiError) {
+	writeJSON(w, apiErr.status, map[string]interface{}{
+		"responseCode":    strconv.Itoa(apiErr.status) + serviceCode + apiErr.caseNo,
+		"responseMessage": apiErr.message,
+	})
+}
+
+// writeScripted writes a scripted response, defaulting to a successful one
+func writeScripted(w http.ResponseWriter, serviceCode string, script ScriptedResponse) {
+	status := script.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	responseCode := script.ResponseCode
+	if responseCode == "" {
+		responseCode = strconv.Itoa(status) + serviceCode + "00"
+	}
+	responseMessage := script.ResponseMessage
+	if responseMessage == "" {
+		responseMessage = http.StatusText(status)
+	}
+
+	fields := make(map[string]interface{}, len(script.Fields)+2)
+	for k, v := range script.Fields {
+		fields[k] = v
+	}
+	fields["responseCode"] = responseCode
+	fields["responseMessage"] = responseMessage
+	writeJSON(w, status, fields)
+}
+
+// createOrder handles the create order (payment host-to-host) operation
+func (g *Gateway) createOrder(body map[string]interface{}) (map[string]interface{}, *apiError) {
+	partnerReferenceNo := stringField(body, "partnerReferenceNo")
+	merchantID := stringField(body, "merchantId")
+	amount, ok := moneyField(body, "amount")
+	if partnerReferenceNo == "" || merchantID == "" || !ok {
+		return nil, snapError(http.StatusBadRequest, "02", "Invalid Mandatory Field partnerReferenceNo/merchantId/amount")
+	}
+	if _, err := parseMinor(amount.Value); err != nil {
+		return nil, snapError(http.StatusBadRequest, "01", "Invalid Field Format amount.value")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, exists := g.orders[partnerReferenceNo]; exists {
+		return nil, snapError(http.StatusConflict, "00", "Inconsistent Request")
+	}
+
+	g.sequence++
+	order := &fakeOrder{
+		PartnerReferenceNo: partnerReferenceNo,
+		ReferenceNo:        fmt.Sprintf("%s%08d", time.Now().Format("20060102"), g.sequence),
+		MerchantID:         merchantID,
+		SubMerchantID:      stringField(body, "subMerchantId"),
+		ExternalStoreID:    stringField(body, "externalStoreId"),
+		Amount:             amount,
+		Status:             StatusInitiated,
+		CreatedTime:        time.Now(),
+	}
+	g.orders[partnerReferenceNo] = order
+
+	return map[string]interface{}{
+		"referenceNo":        order.ReferenceNo,
+		"partnerReferenceNo": order.PartnerReferenceNo,
+		"webRedirectUrl":     g.server.URL + "/checkout?referenceNo=" + order.ReferenceNo,
+	}, nil
+}
+
+// queryPayment handles the query payment (order status) operation
+func (g *Gateway) queryPayment(body map[string]interface{}) (map[string]interface{}, *apiError) {
+	partnerReferenceNo := stringField(body, "originalPartnerReferenceNo")
+	if partnerReferenceNo == "" {
+		return nil, snapError(http.StatusBadRequest, "02", "Invalid Mandatory Field originalPartnerReferenceNo")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	order, ok := g.orders[partnerReferenceNo]
+	if !ok {
+		return nil, snapError(http.StatusNotFound, "01", "Transaction Not Found")
+	}
+
+	return map[string]interface{}{
+		"originalPartnerReferenceNo": order.PartnerReferenceNo,
+		"originalReferenceNo":        order.ReferenceNo,
+		"serviceCode":                "54",
+		"latestTransactionStatus":    order.Status,
+		"transactionStatusDesc":      statusDesc(order.Status),
+		"amount":                     order.Amount,
+		"merchantId":                 order.MerchantID,
+		"createdTime":                jakartaTime(order.CreatedTime),
+	}, nil
+}
+
+// consultPay handles the consult pay (available payment methods) operation
+func (g *Gateway) consultPay(body map[string]interface{}) (map[string]interface{}, *apiError) {
+	if stringField(body, "merchantId") == "" {
+		return nil, snapError(http.StatusBadRequest, "02", "Invalid Mandatory Field merchantId")
+	}
+
+	return map[string]interface{}{
+		"paymentInfos": []map[string]interface{}{
+			{"payMethod": "BALANCE", "payOption": ""},
+			{"payMethod": "VIRTUAL_ACCOUNT", "payOption": "VIRTUAL_ACCOUNT_BCA"},
+			{"payMethod": "VIRTUAL_ACCOUNT", "payOption": "VIRTUAL_ACCOUNT_MANDIRI"},
+			{"payMethod": "NETWORK_PAY", "payOption": "NETWORK_PAY_PG_QRIS"},
+		},
+	}, nil
+}
+
+// cancelOrder handles the cancel order operation
+func (g *Gateway) cancelOrder(body map[string]interface{}) (map[string]interface{}, *apiError) {
+	partnerReferenceNo := stringField(body, "originalPartnerReferenceNo")
+	if partnerReferenceNo == "" {
+		return nil, snapError(http.StatusBadRequest, "02", "Invalid Mandatory Field originalPartnerReferenceNo")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	order, ok := g.orders[partnerReferenceNo]
+	if !ok {
+		return nil, snapError(http.StatusNotFound, "01", "Transaction Not Found")
+	}
+	switch order.Status {
+	case StatusCancelled:
+		return nil, snapError(http.StatusForbidden, "14", "Transaction Cancelled")
+	case StatusSuccess, StatusRefunded:
+		return nil, snapError(http.StatusForbidden, "15", "Transaction Not Permitted. Order already paid")
+	}
+	order.Status = StatusCancelled
+
+	return map[string]interface{}{
+		"originalPartnerReferenceNo": order.PartnerReferenceNo,
+		"originalReferenceNo":        order.ReferenceNo,
+		"cancelTime":                 jakartaNow(),
+	}, nil
+}
+
+// refundOrder handles the refund operation, rejecting refunds above the remaining amount
+func (g *Gateway) refundOrder(body map[string]interface{}) (map[string]interface{}, *apiError) {
+	partnerReferenceNo := stringField(body, "originalPartnerReferenceNo")
+	partnerRefundNo := stringField(body, "partnerRefundNo")
+	amount, ok := moneyField(body, "refundAmount")
+	if partnerReferenceNo == "" || partnerRefundNo == "" || !ok {
+		return nil, snapError(http.StatusBadRequest, "02", "Invalid Mandatory Field originalPartnerReferenceNo/partnerRefundNo/refundAmount")
+	}
+	refundMinor, err := parseMinor(amount.Value)
+	if err != nil || refundMinor <= 0 {
+		return nil, snapError(http.StatusBadRequest, "01", "Invalid Field Format refundAmount.value")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	order, ok := g.orders[partnerReferenceNo]
+	if !ok {
+		return nil, snapError(http.StatusNotFound, "01", "Transaction Not Found")
+	}
+	if order.Status != StatusSuccess && order.Status != StatusRefunded {
+		return nil, snapError(http.StatusForbidden, "15", "Transaction Not Permitted. Order is not paid")
+	}
+	if _, exists := g.refunds[partnerRefundNo]; exists {
+		return nil, snapError(http.StatusConflict, "00", "Inconsistent Request")
+	}
+	orderMinor, _ := parseMinor(order.Amount.Value)
+	if order.Refunded+refundMinor > orderMinor {
+		return nil, snapError(http.StatusForbidden, "13", "Exceeds Transaction Amount Limit")
+	}
+
+	g.sequence++
+	refund := &fakeRefund{
+		PartnerRefundNo: partnerRefundNo,
+		RefundNo:        fmt.Sprintf("R%s%08d", time.Now().Format("20060102"), g.sequence),
+		Amount:          amount,
+	}
+	g.refunds[partnerRefundNo] = refund
+	order.Refunded += refundMinor
+	if order.Refunded == orderMinor {
+		order.Status = StatusRefunded
+	}
+
+	return map[string]interface{}{
+		"originalPartnerReferenceNo": order.PartnerReferenceNo,
+		"originalReferenceNo":        order.ReferenceNo,
+		"refundNo":                   refund.RefundNo,
+		"partnerRefundNo":            refund.PartnerRefundNo,
+		"refundAmount":               refund.Amount,
+		"refundTime":                 jakartaNow(),
+	}, nil
+}
+
+// queryMerchantResource handles the merchant resource query, which uses the Open API envelope
+// The request is signed either with SNAP headers or with the body "signature" over the "request" object
+func (g *Gateway) queryMerchantResource(w http.ResponseWriter, r *http.Request) {
+	body, err := readBody(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, openAPIResult(nil, "F", "PARAM_ILLEGAL", "Bad Request", nil))
+		return
+	}
+	g.record(OpQueryMerchantResource, r, body)
+
+	var envelope struct {
+		Request   json.RawMessage `json:"request"`
+		Signature string          `json:"signature"`
+	}
+	_ = json.Unmarshal(body, &envelope)
+
+	if signature := r.Header.Get("X-SIGNATURE"); signature != "" {
+		err = g.verifySignature(r.Method, r.URL.Path, body, r.Header.Get("X-TIMESTAMP"), signature)
+	} else if len(envelope.Request) > 0 && envelope.Signature != "" {
+		err = g.verify(envelope.Request, envelope.Signature)
+	} else {
+		err = fmt.Errorf("missing signature")
+	}
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, openAPIResult(nil, "F", "INVALID_SIGNATURE", err.Error(), nil))
+		return
+	}
+
+	// Payload may be wrapped in request.body or sent flat
+	var payload struct {
+		Head map[string]interface{} `json:"head"`
+		Body struct {
+			RequestMerchantID        string   `json:"requestMerchantId"`
+			MerchantResourceInfoList []string `json:"merchantResourceInfoList"`
+		} `json:"body"`
+		RequestMerchantID        string   `json:"requestMerchantId"`
+		MerchantResourceInfoList []string `json:"merchantResourceInfoList"`
+	}
+	if len(envelope.Request) > 0 {
+		_ = json.Unmarshal(envelope.Request, &payload)
+	} else {
+		_ = json.Unmarshal(body, &payload)
+	}
+	merchantID := payload.Body.RequestMerchantID
+	resourceTypes := payload.Body.MerchantResourceInfoList
+	if merchantID == "" {
+		merchantID = payload.RequestMerchantID
+		resourceTypes = payload.MerchantResourceInfoList
+	}
+
+	if script, ok := g.nextScript(OpQueryMerchantResource); ok {
+		status := script.StatusCode
+		if status == 0 {
+			status = http.StatusOK
+		}
+		resultStatus := "S"
+		if status >= 300 {
+			resultStatus = "F"
+		}
+		writeJSON(w, status, openAPIResult(payload.Head, resultStatus, script.ResponseCode, script.ResponseMessage, script.Fields))
+		return
+	}
+
+	if merchantID == "" {
+		writeJSON(w, http.StatusBadRequest, openAPIResult(payload.Head, "F", "PARAM_ILLEGAL", "requestMerchantId is required", nil))
+		return
+	}
+
+	g.mu.Lock()
+	infos := make([]map[string]interface{}, 0, len(resourceTypes))
+	for _, resourceType := range resourceTypes {
+		amount, ok := g.balances[resourceType]
+		if !ok {
+			continue
+		}
+		// DANA returns the resource value as a JSON string with amount and currency
+		value, _ := json.Marshal(map[string]string{"amount": amount, "currency": "IDR"})
+		infos = append(infos, map[string]interface{}{
+			"resourceType": resourceType,
+			"value":        string(value),
+		})
+	}
+	g.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, openAPIResult(payload.Head, "S", "SUCCESS", "success", map[string]interface{}{
+		"merchantResourceInformations": infos,
+	}))
+}
+
+// openAPIResult builds an Open API response envelope with resultInfo and extra body fields
+func openAPIResult(requestHead map[string]interface{}, resultStatus, resultCode, resultMsg string, fields map[string]interface{}) map[string]interface{} {
+	if resultCode == "" {
+		resultCode = "SUCCESS"
+	}
+	if resultMsg == "" {
+		resultMsg = strings.ToLower(resultCode)
+	}
+
+	head := map[string]interface{}{
+		"version":  "2.0",
+		"function": "dana.merchant.queryMerchantResource",
+		"respTime": jakartaNow(),
+	}
+	for _, key := range []string{"clientId", "reqMsgId"} {
+		if value, ok := requestHead[key]; ok {
+			head[key] = value
+		}
+	}
+
+	body := map[string]interface{}{
+		"resultInfo": map[string]interface{}{
+			"resultStatus": resultStatus,
+			"resultCodeId": "00000000",
+			"resultCode":   resultCode,
+			"resultMsg":    resultMsg,
+		},
+	}
+	for k, v := range fields {
+		body[k] = v
+	}
+
+	return map[string]interface{}{
+		"response": map[string]interface{}{
+			"head": head,
+			"body": body,
+		},
+		"signature": "",
+	}
+}
+
+// stringField returns a top-level string field of a request body
+func stringField(body map[string]interface{}, key string) string {
+	value, _ := body[key].(string)
+	return value
+}
+
+// moneyField returns a top-level SNAP amount object of a request body
+func moneyField(body map[string]interface{}, key string) (money, bool) {
+	raw, ok := body[key].(map[string]interface{})
+	if !ok {
+		return money{}, false
+	}
+	value, _ := raw["value"].(string)
+	currency, _ := raw["currency"].(string)
+	if value == "" || currency == "" {
+		return money{}, false
+	}
+	return money{Value: value, Currency: currency}, true
+}
+
+// parseMinor parses a SNAP amount value ("10000.00") into minor units
+func parseMinor(value string) (int64, error) {
+	whole, fraction, _ := strings.Cut(value, ".")
+	if len(fraction) != 2 {
+		return 0, fmt.Errorf("amount %q must have two decimals", value)
+	}
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	cents, err := strconv.ParseInt(fraction, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return units*100 + cents, nil
+}
+
+// statusDesc returns the transactionStatusDesc of a status code
+func statusDesc(status string) string {
+	switch status {
+	case StatusSuccess:
+		return "SUCCESS"
+	case StatusInitiated:
+		return "INITIATED"
+	case StatusPaying:
+		return "PAYING"
+	case StatusPending:
+		return "PENDING"
+	case StatusRefunded:
+		return "REFUNDED"
+	case StatusCancelled:
+		return "CANCELLED"
+	case StatusFailed:
+		return "FAILED"
+	default:
+		return "NOT_FOUND"
+	}
+}
+
+// jakartaTime formats t as a DANA timestamp in Jakarta timezone
+func jakartaTime(t time.Time) string {
+	return t.In(time.FixedZone("WIB", 7*60*60)).Format("2006-01-02T15:04:05+07:00")
+}
+
+// jakartaNow returns the current time formatted as a DANA timestamp
+func jakartaNow() string {
+	return jakartaTime(time.Now())
+}
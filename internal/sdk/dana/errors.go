@@ -0,0 +1,113 @@
+package dana
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// DanaError is an error response returned by DANA
+// SNAP responseCode is 7 digits: HTTP status (3), service code (2) and case code (2), e.g. 4005401
+// is HTTP 400, service 54 (create order), case 01 (Invalid Field Format)
+type DanaError struct {
+	HTTPStatus      int
+	ResponseCode    string
+	ResponseMessage string
+}
+
+func (e *DanaError) Error() string {
+	if e.ResponseCode == "" {
+		return fmt.Sprintf("DANA API error (HTTP %d): %s", e.HTTPStatus, e.ResponseMessage)
+	}
+	return fmt.Sprintf("DANA API error %s (HTTP %d): %s", e.ResponseCode, e.HTTPStatus, e.ResponseMessage)
+}
+
+// ServiceCode returns the SNAP service code of the responseCode, e.g. "54" for create order
+func (e *DanaError) ServiceCode() string {
+	if !isSNAPCode(e.ResponseCode) {
+		return ""
+	}
+	return e.ResponseCode[3:5]
+}
+
+// CaseCode returns the SNAP case code of the responseCode, e.g. "01" for Invalid Field Format
+func (e *DanaError) CaseCode() string {
+	if !isSNAPCode(e.ResponseCode) {
+		return ""
+	}
+	return e.ResponseCode[5:]
+}
+
+// isSNAPCode reports whether code is a 7-digit SNAP responseCode
+func isSNAPCode(code string) bool {
+	if len(code) != 7 {
+		return false
+	}
+	_, err := strconv.Atoi(code)
+	return err == nil
+}
+
+// maxErrorMessageLength bounds the message kept from non-JSON error bodies
+const maxErrorMessageLength = 200
+
+// newDanaError builds a DanaError from a DANA error response body
+// SNAP bodies carry responseCode and responseMessage; Open API bodies carry resultInfo
+func newDanaError(httpStatus int, body []byte) *DanaError {
+	var response struct {
+		ResponseCode    string `json:"responseCode"`
+		ResponseMessage string `json:"responseMessage"`
+		Response        struct {
+			Body struct {
+				ResultInfo struct {
+					ResultCode string `json:"resultCode"`
+					ResultMsg  string `json:"resultMsg"`
+				} `json:"resultInfo"`
+			} `json:"body"`
+		} `json:"response"`
+	}
+
+	danaErr := &DanaError{HTTPStatus: httpStatus}
+	if err := json.Unmarshal(body, &response); err != nil {
+		// Gateways in front of DANA may answer with HTML, keep only the start of it
+		message := string(body)
+		if len(message) > maxErrorMessageLength {
+			message = message[:maxErrorMessageLength] + "..."
+		}
+		danaErr.ResponseMessage = message
+	} else if response.ResponseCode != "" {
+		danaErr.ResponseCode = response.ResponseCode
+		danaErr.ResponseMessage = response.ResponseMessage
+	} else {
+		danaErr.ResponseCode = response.Response.Body.ResultInfo.ResultCode
+		danaErr.ResponseMessage = response.Response.Body.ResultInfo.ResultMsg
+	}
+
+	// The SDK does not expose the HTTP status, SNAP codes start with it
+	if danaErr.HTTPStatus == 0 && isSNAPCode(danaErr.ResponseCode) {
+		danaErr.HTTPStatus, _ = strconv.Atoi(danaErr.ResponseCode[:3])
+	}
+	if danaErr.ResponseMessage == "" {
+		danaErr.ResponseMessage = http.StatusText(danaErr.HTTPStatus)
+	}
+	return danaErr
+}
+
+// AsDanaError returns the DANA error response wrapped in err, from the raw Transport or the SDK client
+func AsDanaError(err error) (*DanaError, bool) {
+	var danaErr *DanaError
+	if errors.As(err, &danaErr) {
+		return danaErr, true
+	}
+
+	// SDK errors expose the response body of failed calls
+	var sdkErr interface{ Body() []byte }
+	if errors.As(err, &sdkErr) && len(sdkErr.Body()) > 0 {
+		danaErr = newDanaError(0, sdkErr.Body())
+		if danaErr.ResponseCode != "" {
+			return danaErr, true
+		}
+	}
+	return nil, false
+}
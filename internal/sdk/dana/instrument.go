@@ -0,0 +1,161 @@
+package dana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/riyanathariq/dana-enterprise/internal/logging"
+	"github.com/riyanathariq/dana-enterprise/internal/metrics"
+)
+
+// instrumentedRoundTripper logs and measures every DANA call made by the raw Transport and the SDK client
+// Calls are logged with the inbound request ID of the context and the X-EXTERNAL-ID sent to DANA;
+// headers and bodies are only logged in debug mode, with sensitive fields redacted
+type instrumentedRoundTripper struct {
+	next  http.RoundTripper
+	debug bool
+}
+
+// newHTTPClient creates the HTTP client used for DANA calls
+// Every attempt made by the retry layer is logged and measured separately. The client has no overall timeout,
+// which would cut retries short: each attempt is bounded by Retry.AttemptTimeout, and a call by Retry.Budget
+func newHTTPClient(debug bool, resilience Resilience) *http.Client {
+	return &http.Client{
+		Transport: &retryRoundTripper{
+			next: &instrumentedRoundTripper{
+				next:  http.DefaultTransport,
+				debug: debug,
+			},
+			resilience: resilience,
+		},
+	}
+}
+
+func (t *instrumentedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	op := operation(ctx, req.URL.Path)
+	logger := slog.Default().With(
+		slog.String("operation", op),
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.String("external_id", req.Header.Get("X-EXTERNAL-ID")),
+	)
+
+	if t.debug {
+		var body []byte
+		if req.Body != nil && req.GetBody != nil {
+			if reader, err := req.GetBody(); err == nil {
+				body, _ = io.ReadAll(reader)
+				reader.Close()
+			}
+		}
+		logger.DebugContext(ctx, "DANA request",
+			slog.String("url", req.URL.String()),
+			slog.Any("headers", logging.RedactHeaders(req.Header)),
+			slog.Any("body", logging.RedactJSON(body)),
+		)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	duration := time.Since(start)
+	if err != nil {
+		metrics.ObserveDanaCall(op, 0, metrics.ResponseCodeTransportError, duration)
+		logger.ErrorContext(ctx, "DANA request failed",
+			slog.Int64("duration_ms", duration.Milliseconds()),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	// Read the response to log its responseCode, then hand an unread copy to the caller
+	respBody, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if readErr != nil {
+		metrics.ObserveDanaCall(op, resp.StatusCode, metrics.ResponseCodeTransportError, duration)
+		logger.ErrorContext(ctx, "DANA response could not be read",
+			slog.Int("status", resp.StatusCode),
+			slog.String("error", readErr.Error()),
+		)
+		return resp, nil
+	}
+
+	code := responseCode(respBody)
+	metrics.ObserveDanaCall(op, resp.StatusCode, code, duration)
+
+	level := slog.LevelInfo
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		level = slog.LevelWarn
+	}
+	attrs := []slog.Attr{
+		slog.Int("status", resp.StatusCode),
+		slog.String("response_code", code),
+		slog.Int64("duration_ms", duration.Milliseconds()),
+	}
+	if t.debug {
+		attrs = append(attrs,
+			slog.Any("headers", logging.RedactHeaders(resp.Header)),
+			slog.Any("body", logging.RedactJSON(respBody)),
+		)
+	}
+	logger.LogAttrs(ctx, level, "DANA response", attrs...)
+
+	return resp, nil
+}
+
+// responseCode extracts the SNAP responseCode, or the Open API resultCode, of a DANA response body
+func responseCode(body []byte) string {
+	var response struct {
+		ResponseCode string `json:"responseCode"`
+		Response     struct {
+			Body struct {
+				ResultInfo struct {
+					ResultCode string `json:"resultCode"`
+				} `json:"resultInfo"`
+			} `json:"body"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return ""
+	}
+	if response.ResponseCode != "" {
+		return response.ResponseCode
+	}
+	return response.Response.Body.ResultInfo.ResultCode
+}
+
+type operationKey struct{}
+
+// withOperation labels the DANA calls made with ctx, for endpoints shared by several operations
+func withOperation(ctx context.Context, op string) context.Context {
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+// operationPaths maps DANA endpoint suffixes to the operations called by the SDK client
+var operationPaths = map[string]string{
+	"/debit/status.htm":                        metrics.OperationQueryPayment,
+	"/payment-gateway/consult-pay.htm":         metrics.OperationConsultPay,
+	"/debit/cancel.htm":                        metrics.OperationCancelOrder,
+	"/debit/refund.htm":                        metrics.OperationRefundOrder,
+	"/dana/merchant/queryMerchantResource.htm": metrics.OperationQueryMerchantResource,
+}
+
+// operation returns the DANA operation of a request, from its context or endpoint path
+func operation(ctx context.Context, path string) string {
+	if op, ok := ctx.Value(operationKey{}).(string); ok {
+		return op
+	}
+	for suffix, op := range operationPaths {
+		if strings.HasSuffix(path, suffix) {
+			return op
+		}
+	}
+	return metrics.OperationOther
+}
@@ -0,0 +1,134 @@
+package dana
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/dana-id/dana-go"
+	"github.com/riyanathariq/dana-enterprise/internal/config"
+	danaClient "github.com/riyanathariq/dana-enterprise/package/dana"
+)
+
+// ErrUnknownMerchant is returned when no credentials are registered for a merchant ID
+var ErrUnknownMerchant = errors.New("no DANA credentials registered for merchant")
+
+// Merchant bundles the DANA clients signing with one merchant's credentials
+type Merchant struct {
+	ID        string
+	Config    config.DanaConfig
+	Client    *dana.APIClient // SDK client
+	Transport *Transport      // Raw SNAP client
+}
+
+// Registry holds DANA clients keyed by merchant ID
+type Registry struct {
+	breaker   *Breaker
+	merchants map[string]*Merchant
+	fallback  *Merchant // Default DANA_* credentials
+	strict    bool      // Unknown merchant IDs are rejected instead of using the default credentials
+}
+
+// NewRegistry creates clients for the default credentials and every configured merchant
+// Private keys are parsed once, so call this at startup to fail fast on a malformed key
+func NewRegistry(cfg *config.Config) (*Registry, error) {
+	r := &Registry{
+		breaker:   NewBreaker(cfg.Breaker),
+		merchants: make(map[string]*Merchant, len(cfg.Merchants)+1),
+		strict:    len(cfg.Merchants) > 0,
+	}
+
+	if cfg.Dana.HasCredentials() {
+		merchant, err := newMerchant(cfg.Dana.MerchantID, cfg.Dana, r.resilience(cfg))
+		if err != nil {
+			return nil, err
+		}
+		r.fallback = merchant
+		if merchant.ID != "" {
+			r.merchants[merchant.ID] = merchant
+		}
+	}
+
+	for _, credentials := range cfg.Merchants {
+		merchant, err := newMerchant(credentials.MerchantID, cfg.Dana.ForMerchant(credentials), r.resilience(cfg))
+		if err != nil {
+			return nil, fmt.Errorf("merchant %s: %w", credentials.MerchantID, err)
+		}
+		r.merchants[merchant.ID] = merchant
+	}
+
+	// Without default credentials DANA_MERCHANT_ID selects the default merchant
+	if r.fallback == nil {
+		r.fallback = r.merchants[cfg.Dana.MerchantID]
+	}
+
+	return r, nil
+}
+
+// resilience returns the retry policy and the circuit breaker shared by every merchant
+// DANA outages are not merchant specific, so one breaker guards all credentials
+func (r *Registry) resilience(cfg *config.Config) Resilience {
+	return Resilience{Retry: cfg.Retry, Breaker: r.breaker}
+}
+
+// newMerchant creates the SDK and raw clients for one set of credentials
+func newMerchant(merchantID string, cfg config.DanaConfig, resilience Resilience) (*Merchant, error) {
+	transport, err := NewTransport(cfg, resilience)
+	if err != nil {
+		return nil, err
+	}
+	return &Merchant{
+		ID:        merchantID,
+		Config:    cfg,
+		Client:    danaClient.NewClient(cfg, transport.client),
+		Transport: transport,
+	}, nil
+}
+
+// Get returns the clients for a merchant, or the default merchant when merchantID is empty
+// With a single set of credentials every merchant ID uses them, as before multi-merchant support
+func (r *Registry) Get(merchantID string) (*Merchant, error) {
+	if merchant, ok := r.merchants[merchantID]; ok {
+		return merchant, nil
+	}
+	if r.fallback != nil && (merchantID == "" || !r.strict) {
+		return r.fallback, nil
+	}
+	if merchantID == "" {
+		return nil, fmt.Errorf("%w: merchantId is required, DANA_MERCHANT_ID is not set", ErrUnknownMerchant)
+	}
+	return nil, fmt.Errorf("%w %s", ErrUnknownMerchant, merchantID)
+}
+
+// Breaker returns the circuit breaker guarding DANA calls, nil when disabled
+func (r *Registry) Breaker() *Breaker {
+	return r.breaker
+}
+
+// Default returns the default merchant, which may be nil in a registry without one
+func (r *Registry) Default() *Merchant {
+	return r.fallback
+}
+
+// MerchantIDs returns the registered merchant IDs in sorted order
+func (r *Registry) MerchantIDs() []string {
+	ids := make([]string, 0, len(r.merchants))
+	for id := range r.merchants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// All returns every registered merchant in merchant ID order, followed by the default merchant
+// when it has no merchant ID
+func (r *Registry) All() []*Merchant {
+	merchants := make([]*Merchant, 0, len(r.merchants)+1)
+	for _, id := range r.MerchantIDs() {
+		merchants = append(merchants, r.merchants[id])
+	}
+	if r.fallback != nil && r.fallback.ID == "" {
+		merchants = append(merchants, r.fallback)
+	}
+	return merchants
+}
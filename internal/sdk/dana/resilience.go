@@ -0,0 +1,312 @@
+package dana
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/riyanathariq/dana-enterprise/internal/config"
+	"github.com/riyanathariq/dana-enterprise/internal/metrics"
+)
+
+// ErrCircuitOpen is returned without calling DANA while the circuit breaker is open
+var ErrCircuitOpen = errors.New("DANA circuit breaker is open")
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// Resilience configures retries and the circuit breaker of DANA clients
+type Resilience struct {
+	Retry   config.RetryConfig
+	Breaker *Breaker // Shared by every merchant, nil disables it
+}
+
+// Breaker stops calling DANA after consecutive failures
+// Once OpenTimeout has elapsed a single probe call is let through; its outcome closes or reopens the circuit
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	timeout   time.Duration
+	state     string
+	failures  int
+	openedAt  time.Time
+	probing   bool
+}
+
+// BreakerStatus is a snapshot of the circuit breaker, reported on /ready
+type BreakerStatus struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+// NewBreaker creates a closed circuit breaker, or nil when cfg.FailureThreshold is 0
+func NewBreaker(cfg config.BreakerConfig) *Breaker {
+	if cfg.FailureThreshold <= 0 {
+		return nil
+	}
+	metrics.SetCircuitState(BreakerClosed)
+	return &Breaker{
+		threshold: cfg.FailureThreshold,
+		timeout:   cfg.OpenTimeout,
+		state:     BreakerClosed,
+	}
+}
+
+// Status returns the current breaker state; a nil breaker is always closed
+func (b *Breaker) Status() BreakerStatus {
+	if b == nil {
+		return BreakerStatus{State: BreakerClosed}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{State: b.state, ConsecutiveFailures: b.failures}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
+
+// allow reports whether a call may be sent to DANA at now
+func (b *Breaker) allow(now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if now.Sub(b.openedAt) < b.timeout {
+			return false
+		}
+		b.setState(BreakerHalfOpen)
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+// success records a call that reached DANA and closes the circuit
+func (b *Breaker) success() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	b.setState(BreakerClosed)
+}
+
+// failure records a call failed at now, opening the circuit at the threshold or when the probe fails
+func (b *Breaker) failure(now time.Time) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.openedAt = now
+		b.setState(BreakerOpen)
+	}
+}
+
+// cancel records a call abandoned by the caller, which says nothing about DANA
+func (b *Breaker) cancel() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// setState changes the state, must be called with mu held
+func (b *Breaker) setState(state string) {
+	if b.state == state {
+		return
+	}
+	slog.Warn("DANA circuit breaker state changed", "from", b.state, "to", state, "consecutive_failures", b.failures)
+	b.state = state
+	metrics.SetCircuitState(state)
+}
+
+// retryRoundTripper retries safe DANA calls with exponential backoff and guards every attempt with the breaker
+// Retries resend the same signed request, so DANA sees the same X-EXTERNAL-ID and can deduplicate it.
+// Every attempt has its own Retry.AttemptTimeout, so a hung attempt leaves time for the retries
+type retryRoundTripper struct {
+	next       http.RoundTripper
+	resilience Resilience
+}
+
+func (t *retryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	op := operation(ctx, req.URL.Path)
+	breaker := t.resilience.Breaker
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	attemptReq := req
+	for attempt := 1; ; attempt++ {
+		if !breaker.allow(time.Now()) {
+			return nil, ErrCircuitOpen
+		}
+
+		resp, err := t.attempt(attemptReq)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			breaker.cancel()
+		case err != nil || resp.StatusCode >= 500:
+			breaker.failure(time.Now())
+		default:
+			breaker.success()
+		}
+
+		reason := retryReason(op, resp, err)
+		if reason == "" || ctx.Err() != nil || attempt >= t.resilience.Retry.MaxAttempts || !replayable {
+			return resp, err
+		}
+
+		delay := backoff(t.resilience.Retry, attempt)
+		slog.WarnContext(ctx, "retrying DANA request",
+			slog.String("operation", op),
+			slog.String("external_id", req.Header.Get("X-EXTERNAL-ID")),
+			slog.Int("attempt", attempt),
+			slog.String("reason", reason),
+			slog.Int64("delay_ms", delay.Milliseconds()),
+		)
+		metrics.DanaRetry(op)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		attemptReq = req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+	}
+}
+
+// attempt sends one attempt of a request, bounded by Retry.AttemptTimeout
+// The timeout is released once the response body is closed
+func (t *retryRoundTripper) attempt(req *http.Request) (*http.Response, error) {
+	timeout := t.resilience.Retry.AttemptTimeout
+	if timeout <= 0 {
+		return t.next.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases an attempt's timeout when its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// retryReason returns why a DANA call may be retried, or "" when it must not be
+// Queries are retried on network errors, 5xx and 429. Order creation is only retried when DANA itself reports
+// a retriable responseCode, since a network error may hide an order that was created
+// Cancel and refund are never retried
+func retryReason(op string, resp *http.Response, err error) string {
+	switch op {
+	case metrics.OperationQueryPayment, metrics.OperationConsultPay, metrics.OperationQueryMerchantResource:
+		if err != nil {
+			return "network error"
+		}
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return http.StatusText(resp.StatusCode)
+		}
+	case metrics.OperationCreateOrderHosted, metrics.OperationCreateOrderCustom:
+		if err != nil {
+			return ""
+		}
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if readErr != nil {
+			return ""
+		}
+		if code := responseCode(body); retriableResponseCode(code) {
+			return "responseCode " + code
+		}
+	}
+	return ""
+}
+
+// retriableResponseCode reports whether a SNAP responseCode (HTTP status, service code, case code)
+// asks the partner to retry: Internal Server Error (500xx01), Timeout (504xx00) or Too Many Requests (429xx00)
+func retriableResponseCode(code string) bool {
+	if len(code) != 7 {
+		return false
+	}
+	httpStatus, caseCode := code[:3], code[5:]
+	return (httpStatus == "500" && caseCode == "01") ||
+		(httpStatus == "504" && caseCode == "00") ||
+		(httpStatus == "429" && caseCode == "00")
+}
+
+// backoff returns the delay before the retry following attempt: BaseDelay doubled per attempt,
+// capped at MaxDelay, with equal jitter so concurrent clients do not retry in lockstep
+func backoff(cfg config.RetryConfig, attempt int) time.Duration {
+	delay := cfg.BaseDelay
+	for i := 1; i < attempt && delay < cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > cfg.MaxDelay {
+		delay = cfg.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
@@ -0,0 +1,347 @@
+package dana
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/riyanathariq/dana-enterprise/internal/config"
+	"github.com/riyanathariq/dana-enterprise/internal/metrics"
+)
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// snapResponse returns a DANA response with a SNAP responseCode
+func snapResponse(status int, code string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(`{"responseCode":"` + code + `"}`)),
+	}
+}
+
+func TestRetryReason(t *testing.T) {
+	networkErr := errors.New("connection reset")
+	tests := []struct {
+		op     string
+		status int
+		code   string
+		err    error
+		retry  bool
+	}{
+		{op: metrics.OperationQueryPayment, err: networkErr, retry: true},
+		{op: metrics.OperationQueryPayment, status: 500, code: "5005501", retry: true},
+		{op: metrics.OperationQueryPayment, status: 503, retry: true},
+		{op: metrics.OperationQueryPayment, status: 429, code: "4295500", retry: true},
+		{op: metrics.OperationQueryPayment, status: 404, code: "4045501"},
+		{op: metrics.OperationConsultPay, status: 502, retry: true},
+		{op: metrics.OperationQueryMerchantResource, err: networkErr, retry: true},
+
+		{op: metrics.OperationCreateOrderHosted, status: 500, code: "5005401", retry: true},
+		{op: metrics.OperationCreateOrderHosted, status: 504, code: "5045400", retry: true},
+		{op: metrics.OperationCreateOrderCustom, status: 429, code: "4295400", retry: true},
+		{op: metrics.OperationCreateOrderHosted, status: 500, code: "5005400"},
+		{op: metrics.OperationCreateOrderHosted, status: 504, code: "5045401"},
+		{op: metrics.OperationCreateOrderHosted, status: 409, code: "4095401"},
+		{op: metrics.OperationCreateOrderHosted, status: 502},
+		{op: metrics.OperationCreateOrderCustom, err: networkErr},
+
+		{op: metrics.OperationCancelOrder, status: 500, code: "5005701"},
+		{op: metrics.OperationCancelOrder, err: networkErr},
+		{op: metrics.OperationRefundOrder, status: 500, code: "5005801"},
+		{op: metrics.OperationRefundOrder, status: 429, code: "4295800"},
+		{op: metrics.OperationRefundOrder, err: networkErr},
+	}
+	for _, tt := range tests {
+		var resp *http.Response
+		if tt.err == nil {
+			resp = snapResponse(tt.status, tt.code)
+		}
+		reason := retryReason(tt.op, resp, tt.err)
+		if got := reason != ""; got != tt.retry {
+			t.Errorf("retryReason(%s, %d %s, %v) = %q, want retry %v", tt.op, tt.status, tt.code, tt.err, reason, tt.retry)
+		}
+		// The create order body is read to find the responseCode and must still reach the caller
+		if resp != nil && tt.code != "" {
+			body, _ := io.ReadAll(resp.Body)
+			if !strings.Contains(string(body), tt.code) {
+				t.Errorf("retryReason(%s) consumed the response body, left %q", tt.op, body)
+			}
+		}
+	}
+}
+
+func TestRetryRoundTripper(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		responses []*http.Response // nil is a network error
+		wantCalls int
+		wantCode  string
+	}{
+		{
+			name:      "query retried until success",
+			path:      "/v1.0/debit/status.htm",
+			responses: []*http.Response{nil, snapResponse(503, ""), snapResponse(200, "2005500")},
+			wantCalls: 3,
+			wantCode:  "2005500",
+		},
+		{
+			name:      "query gives up after max attempts",
+			path:      "/v1.0/debit/status.htm",
+			responses: []*http.Response{snapResponse(500, "5005501"), snapResponse(500, "5005501"), snapResponse(500, "5005501"), snapResponse(200, "2005500")},
+			wantCalls: 3,
+			wantCode:  "5005501",
+		},
+		{
+			name:      "create order retried on a retriable responseCode",
+			path:      "/payment-gateway/v1.0/debit/payment-host-to-host.htm",
+			responses: []*http.Response{snapResponse(500, "5005401"), snapResponse(200, "2005400")},
+			wantCalls: 2,
+			wantCode:  "2005400",
+		},
+		{
+			name:      "refund never retried",
+			path:      "/v1.0/debit/refund.htm",
+			responses: []*http.Response{snapResponse(500, "5005801"), snapResponse(200, "2005800")},
+			wantCalls: 1,
+			wantCode:  "5005801",
+		},
+		{
+			name:      "cancel never retried",
+			path:      "/v1.0/debit/cancel.htm",
+			responses: []*http.Response{snapResponse(500, "5005701"), snapResponse(200, "2005700")},
+			wantCalls: 1,
+			wantCode:  "5005701",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			rt := &retryRoundTripper{
+				next: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					resp := tt.responses[calls]
+					calls++
+					if resp == nil {
+						return nil, errors.New("connection reset")
+					}
+					return resp, nil
+				}),
+				resilience: Resilience{Retry: config.RetryConfig{MaxAttempts: 3, AttemptTimeout: time.Second}},
+			}
+			ctx := context.Background()
+			if strings.HasSuffix(tt.path, "payment-host-to-host.htm") {
+				ctx = withOperation(ctx, metrics.OperationCreateOrderHosted)
+			}
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://dana.test"+tt.path, strings.NewReader(`{}`))
+			if err != nil {
+				t.Fatalf("new request: %v", err)
+			}
+
+			resp, err := rt.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("RoundTrip: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if code := responseCode(body); code != tt.wantCode {
+				t.Errorf("responseCode = %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRetryRoundTripperTimesOutEachAttempt(t *testing.T) {
+	calls := 0
+	rt := &retryRoundTripper{
+		next: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				// The first attempt hangs until its own timeout
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			}
+			return snapResponse(200, "2005500"), nil
+		}),
+		resilience: Resilience{Retry: config.RetryConfig{MaxAttempts: 2, AttemptTimeout: 20 * time.Millisecond}},
+	}
+	req, err := http.NewRequest(http.MethodPost, "https://dana.test/v1.0/debit/status.htm", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	defer resp.Body.Close()
+	if calls != 2 || resp.StatusCode != http.StatusOK {
+		t.Errorf("calls = %d, status = %d, want a successful retry after the timed out attempt", calls, resp.StatusCode)
+	}
+}
+
+func TestRetryRoundTripperStopsOnCallerCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	rt := &retryRoundTripper{
+		next: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			cancel()
+			return nil, req.Context().Err()
+		}),
+		resilience: Resilience{
+			Retry:   config.RetryConfig{MaxAttempts: 3, AttemptTimeout: time.Second},
+			Breaker: NewBreaker(config.BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute}),
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://dana.test/v1.0/debit/status.htm", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	if _, err := rt.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("RoundTrip error = %v, want context.Canceled", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if state := rt.resilience.Breaker.Status().State; state != BreakerClosed {
+		t.Errorf("breaker state = %s, a cancelled call must not count as a failure", state)
+	}
+}
+
+func TestRetryRoundTripperOpenBreaker(t *testing.T) {
+	breaker := NewBreaker(config.BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+	breaker.failure(time.Now())
+	calls := 0
+	rt := &retryRoundTripper{
+		next: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			return snapResponse(200, "2005500"), nil
+		}),
+		resilience: Resilience{Retry: config.RetryConfig{MaxAttempts: 3}, Breaker: breaker},
+	}
+	req, err := http.NewRequest(http.MethodGet, "https://dana.test/v1.0/debit/status.htm", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+
+	if _, err := rt.RoundTrip(req); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("RoundTrip error = %v, want ErrCircuitOpen", err)
+	}
+	if calls != 0 {
+		t.Errorf("calls = %d, want none while the circuit is open", calls)
+	}
+}
+
+func TestBreakerStates(t *testing.T) {
+	start := time.Date(2025, 11, 5, 12, 0, 0, 0, time.UTC)
+	b := NewBreaker(config.BreakerConfig{FailureThreshold: 3, OpenTimeout: 30 * time.Second})
+	wantState := func(want string) {
+		t.Helper()
+		if got := b.Status().State; got != want {
+			t.Fatalf("state = %s, want %s", got, want)
+		}
+	}
+
+	// A success resets the consecutive failures
+	b.failure(start)
+	b.failure(start)
+	b.success()
+	b.failure(start)
+	b.failure(start)
+	wantState(BreakerClosed)
+
+	// The threshold opens the circuit
+	b.failure(start)
+	wantState(BreakerOpen)
+	if b.allow(start.Add(29 * time.Second)) {
+		t.Fatalf("allow before OpenTimeout = true")
+	}
+
+	// After OpenTimeout a single probe is let through
+	if !b.allow(start.Add(30 * time.Second)) {
+		t.Fatalf("allow after OpenTimeout = false")
+	}
+	wantState(BreakerHalfOpen)
+	if b.allow(start.Add(30 * time.Second)) {
+		t.Fatalf("allow during the probe = true")
+	}
+
+	// A failed probe reopens the circuit for another OpenTimeout
+	reopened := start.Add(31 * time.Second)
+	b.failure(reopened)
+	wantState(BreakerOpen)
+	if b.allow(reopened.Add(29 * time.Second)) {
+		t.Fatalf("allow before OpenTimeout after reopening = true")
+	}
+
+	// A cancelled probe frees the slot for another probe
+	if !b.allow(reopened.Add(30 * time.Second)) {
+		t.Fatalf("allow after OpenTimeout = false")
+	}
+	b.cancel()
+	wantState(BreakerHalfOpen)
+	if !b.allow(reopened.Add(30 * time.Second)) {
+		t.Fatalf("allow after a cancelled probe = false")
+	}
+
+	// A successful probe closes the circuit
+	b.success()
+	wantState(BreakerClosed)
+	if status := b.Status(); status.ConsecutiveFailures != 0 || status.OpenedAt != nil {
+		t.Errorf("status after closing = %+v", status)
+	}
+	if !b.allow(reopened.Add(31 * time.Second)) {
+		t.Errorf("allow when closed = false")
+	}
+}
+
+func TestNilBreaker(t *testing.T) {
+	b := NewBreaker(config.BreakerConfig{FailureThreshold: 0})
+	if b != nil {
+		t.Fatalf("NewBreaker with threshold 0 = %v, want nil", b)
+	}
+	b.failure(time.Now())
+	if !b.allow(time.Now()) || b.Status().State != BreakerClosed {
+		t.Errorf("nil breaker must always allow calls")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cfg := config.RetryConfig{BaseDelay: 200 * time.Millisecond, MaxDelay: time.Second}
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, 200 * time.Millisecond},
+		{2, 400 * time.Millisecond},
+		{3, 800 * time.Millisecond},
+		{4, time.Second},
+		{10, time.Second},
+	}
+	for _, tt := range tests {
+		for range 100 {
+			delay := backoff(cfg, tt.attempt)
+			if delay < tt.max/2 || delay > tt.max {
+				t.Fatalf("backoff(attempt %d) = %s, want within [%s, %s]", tt.attempt, delay, tt.max/2, tt.max)
+			}
+		}
+	}
+
+	if delay := backoff(config.RetryConfig{}, 1); delay != 0 {
+		t.Errorf("backoff without a base delay = %s, want 0", delay)
+	}
+}
@@ -0,0 +1,96 @@
+package dana
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Signer produces SNAP X-SIGNATURE values with a private key parsed once
+type Signer struct {
+	privateKey *rsa.PrivateKey
+}
+
+// ParsePrivateKey parses a PEM private key (PKCS1 or PKCS8), accepting "\n" literals as newlines
+func ParsePrivateKey(privateKeyStr string) (*rsa.PrivateKey, error) {
+	if privateKeyStr == "" {
+		return nil, fmt.Errorf("DANA_PRIVATE_KEY is required")
+	}
+
+	// Normalize private key (handle \n literals)
+	privateKeyStr = strings.ReplaceAll(privateKeyStr, "\\n", "\n")
+	if !strings.Contains(privateKeyStr, "-----BEGIN") {
+		return nil, fmt.Errorf("invalid private key format: missing PEM headers")
+	}
+
+	block, _ := pem.Decode([]byte(privateKeyStr))
+	if block == nil {
+		return nil, fmt.Errorf("failed to parse PEM block containing private key")
+	}
+
+	// Try PKCS1 first
+	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err == nil {
+		return privateKey, nil
+	}
+
+	// Try PKCS8
+	pkcs8Key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	rsaKey, ok := pkcs8Key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("parsed key is not an RSA private key")
+	}
+	return rsaKey, nil
+}
+
+// NewSigner creates a Signer from a PEM private key
+func NewSigner(privateKeyStr string) (*Signer, error) {
+	privateKey, err := ParsePrivateKey(privateKeyStr)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{privateKey: privateKey}, nil
+}
+
+// StringToSign builds the SNAP asymmetric string to sign
+// Format: "<HTTP METHOD>:<RELATIVE PATH URL>:<LOWERCASE_HEX_ENCODED_SHA_256(MINIFIED_HTTP_BODY)>:<X-TIMESTAMP>"
+func StringToSign(method, path string, minifiedBody []byte, timestamp string) string {
+	hashedPayload := sha256.Sum256(minifiedBody)
+	return fmt.Sprintf("%s:%s:%x:%s", strings.ToUpper(method), path, hashedPayload, timestamp)
+}
+
+// Sign signs a request with SHA256withRSA and returns the base64 X-SIGNATURE
+// Body must already be minified; GET requests sign an empty body
+func (s *Signer) Sign(method, path string, minifiedBody []byte, timestamp string) (string, error) {
+	hashed := sha256.Sum256([]byte(StringToSign(method, path, minifiedBody, timestamp)))
+
+	// Sign the hashed data with PKCS1v15
+	signatureBytes, err := rsa.SignPKCS1v15(rand.Reader, s.privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign data: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(signatureBytes), nil
+}
+
+// jakartaTimestamp returns the current time as X-TIMESTAMP in Jakarta timezone
+func jakartaTimestamp() string {
+	jkt, err := time.LoadLocation("Asia/Jakarta")
+	var jktTime time.Time
+	if err != nil {
+		jktTime = time.Now().UTC().Add(7 * time.Hour)
+	} else {
+		jktTime = time.Now().In(jkt)
+	}
+	return jktTime.Format("2006-01-02T15:04:05+07:00")
+}
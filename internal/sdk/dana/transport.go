@@ -0,0 +1,132 @@
+package dana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	uuid "github.com/google/uuid"
+	"github.com/riyanathariq/dana-enterprise/internal/config"
+)
+
+// Transport sends SNAP-signed HTTP requests to DANA
+type Transport struct {
+	baseURL   string
+	env       string
+	partnerID string
+	channelID string
+	origin    string
+	debug     bool
+	signer    *Signer
+	client    *http.Client
+}
+
+// NewTransport creates a Transport from validated configuration
+// The private key is parsed once, so call this at startup to fail fast on a malformed key
+func NewTransport(cfg config.DanaConfig, resilience Resilience) (*Transport, error) {
+	signer, err := NewSigner(cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Transport{
+		baseURL:   cfg.BaseURL(),
+		env:       cfg.Env,
+		partnerID: cfg.PartnerID,
+		channelID: cfg.ChannelID,
+		origin:    cfg.Origin,
+		debug:     cfg.Debug,
+		signer:    signer,
+		client:    newHTTPClient(cfg.Debug, resilience),
+	}, nil
+}
+
+// Do signs and sends a request to path, decoding a successful JSON response into out
+// payload is marshalled and minified for POST-like methods; pass nil for GET
+// Error responses from DANA are returned as *DanaError
+func (t *Transport) Do(ctx context.Context, method, path string, payload interface{}, out interface{}) error {
+	var bodyBytes []byte
+	if payload != nil {
+		// Marshal request body to JSON (minified, no indentation) - same as SDK
+		marshalled, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, marshalled); err != nil {
+			return fmt.Errorf("failed to compact JSON: %w", err)
+		}
+		bodyBytes = compacted.Bytes()
+	}
+
+	timestamp := jakartaTimestamp()
+	signature, err := t.signer.Sign(method, path, bodyBytes, timestamp)
+	if err != nil {
+		return err
+	}
+
+	// Generate external ID
+	externalID := "sdk" + uuid.New().String()[3:]
+
+	endpoint := t.baseURL + path
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-TIMESTAMP", timestamp)
+	req.Header.Set("X-SIGNATURE", signature)
+	req.Header.Set("X-PARTNER-ID", t.partnerID)
+	req.Header.Set("X-EXTERNAL-ID", externalID)
+	req.Header.Set("CHANNEL-ID", t.channelID)
+
+	// Set optional headers
+	if t.origin != "" {
+		req.Header.Set("ORIGIN", t.origin)
+	}
+
+	// Set debug mode if enabled
+	if t.debug && strings.ToLower(t.env) == "sandbox" {
+		req.Header.Set("X-Debug-Mode", "true")
+	}
+
+	slog.DebugContext(ctx, "DANA string to sign",
+		slog.String("external_id", externalID),
+		slog.String("string_to_sign", StringToSign(method, path, bodyBytes, timestamp)),
+	)
+
+	// Execute request
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Check HTTP status
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newDanaError(resp.StatusCode, respBody)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	// Parse response
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package dana
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dana-id/dana-go/payment_gateway/v1"
+)
+
+// ErrInvalidSignature is returned when the X-SIGNATURE of an inbound DANA request does not match
+var ErrInvalidSignature = errors.New("invalid DANA signature")
+
+// ErrInvalidPayload is returned when the body of an inbound DANA request is not valid JSON
+var ErrInvalidPayload = errors.New("invalid DANA payload")
+
+// FinishNotifyRequest represents the finish-notify payload DANA sends to the NOTIFICATION url
+type FinishNotifyRequest struct {
+	OriginalPartnerReferenceNo string                 `json:"originalPartnerReferenceNo"`
+	OriginalReferenceNo        string                 `json:"originalReferenceNo,omitempty"`
+	OriginalExternalId         string                 `json:"originalExternalId,omitempty"`
+	MerchantId                 string                 `json:"merchantId"`
+	SubMerchantId              string                 `json:"subMerchantId,omitempty"`
+	Amount                     payment_gateway.Money  `json:"amount"`
+	LatestTransactionStatus    string                 `json:"latestTransactionStatus"`
+	TransactionStatusDesc      string                 `json:"transactionStatusDesc,omitempty"`
+	CreatedTime                string                 `json:"createdTime,omitempty"`
+	FinishedTime               string                 `json:"finishedTime,omitempty"`
+	ExternalStoreId            string                 `json:"externalStoreId,omitempty"`
+	AdditionalInfo             map[string]interface{} `json:"additionalInfo,omitempty"`
+}
+
+// ParsePublicKey parses DANA public key in PEM format (PKIX or PKCS1)
+func ParsePublicKey(publicKeyStr string) (*rsa.PublicKey, error) {
+	// Normalize public key (handle \n literals)
+	publicKeyStr = strings.ReplaceAll(publicKeyStr, "\\n", "\n")
+	if !strings.Contains(publicKeyStr, "-----BEGIN") {
+		return nil, fmt.Errorf("invalid public key format: missing PEM headers")
+	}
+
+	block, _ := pem.Decode([]byte(publicKeyStr))
+	if block == nil {
+		return nil, fmt.Errorf("failed to parse PEM block containing public key")
+	}
+
+	// Try PKIX first
+	if pkixKey, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		rsaKey, ok := pkixKey.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("parsed key is not an RSA public key")
+		}
+		return rsaKey, nil
+	}
+
+	// Try PKCS1
+	rsaKey, err := x509.ParsePKCS1PublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return rsaKey, nil
+}
+
+// VerifyWebhookSignature verifies X-SIGNATURE of an inbound DANA request against the DANA public key
+// String to sign: "<HTTP METHOD>:<RELATIVE PATH URL>:<LOWERCASE_HEX_ENCODED_SHA_256(MINIFIED_HTTP_BODY)>:<X-TIMESTAMP>"
+// X-TIMESTAMP must be within maxSkew of now, so a captured request cannot be replayed later
+func VerifyWebhookSignature(publicKeyStr, method, path string, body []byte, timestamp, signature string, maxSkew time.Duration, now time.Time) error {
+	if timestamp == "" || signature == "" {
+		return fmt.Errorf("%w: X-TIMESTAMP and X-SIGNATURE headers are required", ErrInvalidSignature)
+	}
+	signedAt, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return fmt.Errorf("%w: X-TIMESTAMP is not a valid timestamp", ErrInvalidSignature)
+	}
+	if skew := now.Sub(signedAt); skew > maxSkew || skew < -maxSkew {
+		return fmt.Errorf("%w: X-TIMESTAMP %s is outside the accepted window of %s", ErrInvalidSignature, timestamp, maxSkew)
+	}
+
+	if publicKeyStr == "" {
+		return fmt.Errorf("DANA_PUBLIC_KEY is required")
+	}
+	publicKey, err := ParsePublicKey(publicKeyStr)
+	if err != nil {
+		return err
+	}
+
+	// Minify body the same way DANA does before hashing
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, body); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+
+	stringToSign := StringToSign(method, path, compacted.Bytes(), timestamp)
+
+	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("%w: signature is not valid base64", ErrInvalidSignature)
+	}
+
+	hashed := sha256.Sum256([]byte(stringToSign))
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hashed[:], signatureBytes); err != nil {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// ParseFinishNotify verifies the signature and timestamp of a finish-notify request and decodes its payload
+func ParseFinishNotify(publicKeyStr, method, path string, body []byte, timestamp, signature string, maxSkew time.Duration, now time.Time) (*FinishNotifyRequest, error) {
+	if err := VerifyWebhookSignature(publicKeyStr, method, path, body, timestamp, signature, maxSkew, now); err != nil {
+		return nil, err
+	}
+
+	var notify FinishNotifyRequest
+	if err := json.Unmarshal(body, &notify); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+
+	return &notify, nil
+}
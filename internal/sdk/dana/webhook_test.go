@@ -0,0 +1,94 @@
+package dana
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testKeyPair generates a key pair, returning the private and public keys in PEM format
+func testKeyPair(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return string(privatePEM), string(publicPEM)
+}
+
+func TestVerifyWebhookSignatureTimestampWindow(t *testing.T) {
+	privateKey, publicKey := testKeyPair(t)
+	signer, err := NewSigner(privateKey)
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+
+	const path = "/api/v1/webhook/dana/finish-notify"
+	body := []byte(`{"originalPartnerReferenceNo":"ORDER-001","latestTransactionStatus":"00"}`)
+	now := time.Date(2025, 11, 5, 12, 0, 0, 0, time.FixedZone("WIB", 7*60*60))
+	maxSkew := 5 * time.Minute
+
+	tests := []struct {
+		name      string
+		timestamp string
+		wantErr   bool
+	}{
+		{"current", now.Format(time.RFC3339), false},
+		{"within window", now.Add(-4 * time.Minute).Format(time.RFC3339), false},
+		{"slightly ahead", now.Add(time.Minute).Format(time.RFC3339), false},
+		{"replayed", now.Add(-6 * time.Minute).Format(time.RFC3339), true},
+		{"far in the future", now.Add(time.Hour).Format(time.RFC3339), true},
+		{"malformed", "05-11-2025 12:00:00", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature, err := signer.Sign("POST", path, body, tt.timestamp)
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+
+			err = VerifyWebhookSignature(publicKey, "POST", path, body, tt.timestamp, signature, maxSkew, now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSignature) {
+					t.Fatalf("err = %v, want ErrInvalidSignature", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestVerifyWebhookSignatureRejectsTamperedTimestamp(t *testing.T) {
+	privateKey, publicKey := testKeyPair(t)
+	signer, err := NewSigner(privateKey)
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+
+	const path = "/api/v1/webhook/dana/finish-notify"
+	body := []byte(`{"originalPartnerReferenceNo":"ORDER-001"}`)
+	now := time.Now()
+	signature, err := signer.Sign("POST", path, body, now.Add(-time.Hour).Format(time.RFC3339))
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	// A replayed request with a refreshed X-TIMESTAMP no longer matches its signature
+	err = VerifyWebhookSignature(publicKey, "POST", path, body, now.Format(time.RFC3339), signature, 5*time.Minute, now)
+	if !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("err = %v, want ErrInvalidSignature", err)
+	}
+}
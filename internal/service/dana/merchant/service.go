@@ -2,20 +2,115 @@ package merchant
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/dana-id/dana-go/merchant_management/v1"
-	"github.com/riyanathariq/dana-enterprise/package/dana"
+	"github.com/riyanathariq/dana-enterprise/internal/config"
+	danaSDK "github.com/riyanathariq/dana-enterprise/internal/sdk/dana"
+	"golang.org/x/sync/singleflight"
 )
 
-type Service struct{}
+type Service struct {
+	merchants *danaSDK.Registry
+	cfg       config.MerchantInfoConfig
 
-func NewService() *Service {
-	return &Service{}
+	group   singleflight.Group // De-duplicates concurrent queries of the same merchant
+	cacheMu sync.Mutex
+	cache   map[string]*Info // keyed by merchant ID
 }
 
-func (s *Service) GetMerchantInfo(ctx context.Context, merchantID string) (*merchant_management.QueryMerchantResourceResponse, error) {
-	danaClient := dana.InitData()
-	merchantResource, _, err := danaClient.MerchantManagementAPI.QueryMerchantResource(ctx).
+func NewService(merchants *danaSDK.Registry, cfg config.MerchantInfoConfig) *Service {
+	return &Service{
+		merchants: merchants,
+		cfg:       cfg,
+		cache:     make(map[string]*Info),
+	}
+}
+
+// Info is the resource information of a merchant, as queried at DANA at FetchedAt
+type Info struct {
+	Resource  *merchant_management.QueryMerchantResourceResponse
+	FetchedAt time.Time
+	Cached    bool // Served from the cache rather than queried for this request
+}
+
+// ExpiresAt returns the time the cached information is queried again
+func (i *Info) ExpiresAt(ttl time.Duration) time.Time {
+	return i.FetchedAt.Add(ttl)
+}
+
+// GetMerchantInfo returns the merchant's resource information, reused for cfg.CacheTTL
+// fresh skips the cache; concurrent queries of a merchant share a single DANA call either way
+// Failed queries are not cached
+func (s *Service) GetMerchantInfo(ctx context.Context, merchantID string, fresh bool) (*Info, error) {
+	if !fresh {
+		if info, ok := s.cached(merchantID, time.Now()); ok {
+			return info, nil
+		}
+	}
+
+	// The shared call must outlive the request that started it, as other requests may be waiting on it
+	result := s.group.DoChan(merchantID, func() (interface{}, error) {
+		resource, err := s.queryMerchantResource(context.WithoutCancel(ctx), merchantID)
+		if err != nil {
+			return nil, err
+		}
+		info := &Info{Resource: resource, FetchedAt: time.Now()}
+		s.store(merchantID, info)
+		return info, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-result:
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		return r.Val.(*Info), nil
+	}
+}
+
+// cached returns the unexpired cached information of a merchant
+func (s *Service) cached(merchantID string, now time.Time) (*Info, bool) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	info, ok := s.cache[merchantID]
+	if !ok || !now.Before(info.ExpiresAt(s.cfg.CacheTTL)) {
+		return nil, false
+	}
+	hit := *info
+	hit.Cached = true
+	return &hit, true
+}
+
+// store caches the information of a merchant and drops expired entries,
+// which would otherwise pile up for merchant IDs served by the default credentials
+func (s *Service) store(merchantID string, info *Info) {
+	if s.cfg.CacheTTL <= 0 {
+		return
+	}
+
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	for id, entry := range s.cache {
+		if !info.FetchedAt.Before(entry.ExpiresAt(s.cfg.CacheTTL)) {
+			delete(s.cache, id)
+		}
+	}
+	s.cache[merchantID] = info
+}
+
+func (s *Service) queryMerchantResource(ctx context.Context, merchantID string) (*merchant_management.QueryMerchantResourceResponse, error) {
+	merchant, err := s.merchants.Get(merchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	merchantResource, _, err := merchant.Client.MerchantManagementAPI.QueryMerchantResource(ctx).
 		QueryMerchantResourceRequest(merchant_management.QueryMerchantResourceRequest{
 			RequestMerchantId: merchantID,
 			MerchantResourceInfoList: []string{
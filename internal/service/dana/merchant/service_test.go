@@ -0,0 +1,102 @@
+package merchant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/riyanathariq/dana-enterprise/internal/config"
+	danaSDK "github.com/riyanathariq/dana-enterprise/internal/sdk/dana"
+	"github.com/riyanathariq/dana-enterprise/internal/sdk/dana/danatest"
+)
+
+// newGatewayService creates a service querying a fake DANA gateway
+func newGatewayService(t *testing.T) (*Service, *danatest.Gateway) {
+	t.Helper()
+	gw := danatest.NewGateway()
+	t.Cleanup(gw.Close)
+	gw.Setenv(t.Setenv)
+	t.Setenv("DANA_RETRY_MAX_ATTEMPTS", "1")
+	t.Setenv("DANA_BREAKER_FAILURE_THRESHOLD", "0")
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+	merchants, err := danaSDK.NewRegistry(cfg)
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	return NewService(merchants, cfg.Merchant), gw
+}
+
+// resources returns the resource values of merchant info keyed by resource type
+func resources(info *Info) map[string]string {
+	values := make(map[string]string)
+	for _, resource := range info.Resource.Response.Body.MerchantResourceInformations {
+		values[resource.GetResourceType()] = resource.GetValue()
+	}
+	return values
+}
+
+func TestGetMerchantInfo(t *testing.T) {
+	s, gw := newGatewayService(t)
+	gw.SetBalance("MERCHANT_AVAILABLE_BALANCE", "123456.78")
+
+	info, err := s.GetMerchantInfo(context.Background(), danatest.TestMerchantID, false)
+	if err != nil {
+		t.Fatalf("GetMerchantInfo: %v", err)
+	}
+	if info.Cached {
+		t.Error("first query is served from the cache")
+	}
+	values := resources(info)
+	if len(values) != 3 {
+		t.Errorf("resources = %v, want deposit, available and total balance", values)
+	}
+	if want := `{"amount":"123456.78","currency":"IDR"}`; values["MERCHANT_AVAILABLE_BALANCE"] != want {
+		t.Errorf("available balance = %s, want %s", values["MERCHANT_AVAILABLE_BALANCE"], want)
+	}
+}
+
+func TestGetMerchantInfoCaches(t *testing.T) {
+	s, gw := newGatewayService(t)
+	ctx := context.Background()
+
+	if _, err := s.GetMerchantInfo(ctx, danatest.TestMerchantID, false); err != nil {
+		t.Fatalf("GetMerchantInfo: %v", err)
+	}
+	info, err := s.GetMerchantInfo(ctx, danatest.TestMerchantID, false)
+	if err != nil {
+		t.Fatalf("GetMerchantInfo: %v", err)
+	}
+	if !info.Cached {
+		t.Error("second query is not served from the cache")
+	}
+	if n := len(gw.Requests(danatest.OpQueryMerchantResource)); n != 1 {
+		t.Errorf("merchant resource requests = %d, want 1", n)
+	}
+
+	// fresh bypasses the cache
+	if _, err := s.GetMerchantInfo(ctx, danatest.TestMerchantID, true); err != nil {
+		t.Fatalf("GetMerchantInfo fresh: %v", err)
+	}
+	if n := len(gw.Requests(danatest.OpQueryMerchantResource)); n != 2 {
+		t.Errorf("merchant resource requests = %d, want 2", n)
+	}
+}
+
+func TestGetMerchantInfoReturnsScriptedError(t *testing.T) {
+	s, gw := newGatewayService(t)
+	gw.Enqueue(danatest.OpQueryMerchantResource, danatest.ScriptedResponse{StatusCode: 400, ResponseCode: "PARAM_ILLEGAL", ResponseMessage: "Invalid merchant"})
+
+	_, err := s.GetMerchantInfo(context.Background(), danatest.TestMerchantID, false)
+	danaErr, ok := danaSDK.AsDanaError(err)
+	if !ok || danaErr.ResponseCode != "PARAM_ILLEGAL" {
+		t.Fatalf("err = %v, want DANA error PARAM_ILLEGAL", err)
+	}
+
+	// Failed queries are not cached
+	if _, err := s.GetMerchantInfo(context.Background(), danatest.TestMerchantID, false); err != nil {
+		t.Fatalf("GetMerchantInfo after error: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package order
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dana-id/dana-go/payment_gateway/v1"
+	"github.com/riyanathariq/dana-enterprise/internal/errs"
+	"github.com/riyanathariq/dana-enterprise/internal/money"
+)
+
+// CreatedOrder is an order created at DANA, with what the buyer needs to pay it
+type CreatedOrder struct {
+	PartnerReferenceNo string
+	ReferenceNo        string // DANA reference number
+	MerchantID         string
+	CheckoutType       string
+	Amount             money.Money
+	PayOptionDetails   []payment_gateway.PayOptionDetail // Pay options the order was created with, empty for hosted checkout
+	WebRedirectURL     string                            // DANA payment page, for hosted checkout and redirect pay options
+	PaymentCode        string                            // VA number or QRIS payload, depending on the pay option
+	ValidUpTo          string                            // Expiry sent to DANA (YYYY-MM-DDTHH:mm:ss+07:00)
+}
+
+// createOrderAdditionalInfo is the additionalInfo of a create order response
+type createOrderAdditionalInfo struct {
+	PaymentCode string `json:"paymentCode"`
+}
+
+// readPaymentCode reads the payment code from the additionalInfo of a create order response
+// Only VA and QRIS pay options have one, other orders are paid through WebRedirectURL
+func readPaymentCode(additionalInfo json.RawMessage) (string, error) {
+	if len(additionalInfo) == 0 || string(additionalInfo) == "null" {
+		return "", nil
+	}
+	var info createOrderAdditionalInfo
+	if err := json.Unmarshal(additionalInfo, &info); err != nil {
+		return "", fmt.Errorf("%w: additionalInfo: %v", errs.ErrMalformedCreateOrderResponse, err)
+	}
+	return info.PaymentCode, nil
+}
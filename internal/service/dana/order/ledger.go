@@ -0,0 +1,106 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/riyanathariq/dana-enterprise/internal/auth"
+	"github.com/riyanathariq/dana-enterprise/internal/errs"
+	"github.com/riyanathariq/dana-enterprise/internal/metrics"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
+)
+
+// statusFromDANA maps DANA latestTransactionStatus codes to ledger statuses
+func statusFromDANA(code string) string {
+	switch code {
+	case "00":
+		return repository.StatusSuccess
+	case "01":
+		return repository.StatusInitiated
+	case "02":
+		return repository.StatusPaying
+	case "03":
+		return repository.StatusPending
+	case "04":
+		return repository.StatusRefunded
+	case "05":
+		return repository.StatusCancelled
+	case "06":
+		return repository.StatusFailed
+	}
+	return ""
+}
+
+// recordOrder writes a newly created order to the ledger
+// Failures are logged and counted only, the order already exists in DANA at this point
+func (s *Service) recordOrder(ctx context.Context, params CreateOrderRequestParams, created *CreatedOrder) {
+	metrics.OrderCreated(created.CheckoutType)
+
+	order := &repository.Order{
+		PartnerReferenceNo: created.PartnerReferenceNo,
+		MerchantID:         created.MerchantID,
+		AmountValue:        created.Amount.String(),
+		AmountCurrency:     created.Amount.Currency(),
+		AmountMinor:        created.Amount.Minor(),
+		CheckoutType:       created.CheckoutType,
+		ReferenceNo:        created.ReferenceNo,
+		WebRedirectURL:     created.WebRedirectURL,
+		Status:             repository.StatusInitiated,
+		ValidUpTo:          created.ValidUpTo,
+		ClientID:           auth.ClientID(ctx),
+	}
+	if params.SubMerchantID != nil {
+		order.SubMerchantID = *params.SubMerchantID
+	}
+	if params.ExternalStoreID != nil {
+		order.ExternalStoreID = *params.ExternalStoreID
+	}
+
+	if err := s.orders.Create(ctx, order); err != nil {
+		metrics.LedgerWriteFailed(metrics.LedgerCreateOrder)
+		slog.WarnContext(ctx, "failed to record order", "partner_reference_no", params.PartnerReferenceNo, "error", err)
+	}
+}
+
+// updateOrderStatus updates the ledger status of an order, ignoring orders unknown to the ledger
+// Orders in a terminal status keep it, see repository.CanTransition
+func (s *Service) updateOrderStatus(ctx context.Context, partnerReferenceNo, status string) {
+	if status == "" {
+		return
+	}
+
+	order, err := s.orders.Get(ctx, partnerReferenceNo)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			metrics.LedgerWriteFailed(metrics.LedgerUpdateOrder)
+			slog.WarnContext(ctx, "failed to load order", "partner_reference_no", partnerReferenceNo, "error", err)
+		}
+		return
+	}
+	if order.Status == status {
+		return
+	}
+	if !repository.CanTransition(order.Status, status) {
+		slog.DebugContext(ctx, "ignoring status of an order in a terminal status",
+			"partner_reference_no", partnerReferenceNo, "status", order.Status, "ignored_status", status)
+		return
+	}
+
+	order.Status = status
+	if err := s.orders.Update(ctx, order); err != nil {
+		// ErrFinalStatus means a concurrent update moved the order to a terminal status first
+		if !errors.Is(err, errs.ErrFinalStatus) {
+			metrics.LedgerWriteFailed(metrics.LedgerUpdateOrder)
+			slog.WarnContext(ctx, "failed to update order", "partner_reference_no", partnerReferenceNo, "error", err)
+		}
+		return
+	}
+
+	switch status {
+	case repository.StatusSuccess:
+		metrics.OrderPaid(order.CheckoutType)
+	case repository.StatusExpired:
+		metrics.OrderExpired(order.CheckoutType)
+	}
+}
@@ -0,0 +1,110 @@
+package order
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/riyanathariq/dana-enterprise/internal/config"
+	"github.com/riyanathariq/dana-enterprise/internal/metrics"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
+)
+
+// failingUpdates is a ledger whose updates always fail
+type failingUpdates struct {
+	*repository.MemoryOrderRepository
+}
+
+func (failingUpdates) Update(ctx context.Context, order *repository.Order) error {
+	return errors.New("disk I/O error")
+}
+
+// metricValue scrapes the value of a sample from the metrics handler, 0 if it is missing
+func metricValue(t *testing.T, sample string) float64 {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	scanner := bufio.NewScanner(recorder.Body)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), sample+" "); ok {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				t.Fatalf("parse %s: %v", sample, err)
+			}
+			return v
+		}
+	}
+	return 0
+}
+
+func createTestOrder(t *testing.T, orders repository.OrderRepository, ref, status string) {
+	t.Helper()
+	err := orders.Create(context.Background(), &repository.Order{
+		PartnerReferenceNo: ref,
+		MerchantID:         "216620000000000000000",
+		AmountValue:        "10000.00",
+		AmountCurrency:     "IDR",
+		AmountMinor:        1000000,
+		CheckoutType:       repository.CheckoutTypeHosted,
+		Status:             status,
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+}
+
+func TestUpdateOrderStatusKeepsTerminalStatus(t *testing.T) {
+	ctx := context.Background()
+	ledger := repository.NewMemoryOrderRepository()
+	service := NewService(&config.Config{}, nil, ledger, ledger)
+
+	tests := []struct {
+		name    string
+		initial string
+		updates []string
+		want    string
+	}{
+		{"pending order is paid", repository.StatusPending, []string{repository.StatusSuccess}, repository.StatusSuccess},
+		{"paid order is refunded", repository.StatusInitiated, []string{repository.StatusSuccess, repository.StatusRefunded}, repository.StatusRefunded},
+		{"paid order is not cancelled", repository.StatusPaying, []string{repository.StatusSuccess, repository.StatusCancelled}, repository.StatusSuccess},
+		{"expired order is not cancelled", repository.StatusPending, []string{repository.StatusExpired, repository.StatusCancelled}, repository.StatusExpired},
+		{"late query does not reopen", repository.StatusInitiated, []string{repository.StatusFailed, repository.StatusPending}, repository.StatusFailed},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref := "ORDER-" + strconv.Itoa(i)
+			createTestOrder(t, ledger, ref, tt.initial)
+
+			for _, status := range tt.updates {
+				service.updateOrderStatus(ctx, ref, status)
+			}
+
+			order, err := ledger.Get(ctx, ref)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if order.Status != tt.want {
+				t.Fatalf("status = %s, want %s", order.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateOrderStatusCountsWriteFailures(t *testing.T) {
+	ctx := context.Background()
+	ledger := failingUpdates{repository.NewMemoryOrderRepository()}
+	service := NewService(&config.Config{}, nil, ledger, ledger)
+	createTestOrder(t, ledger, "ORDER-001", repository.StatusInitiated)
+
+	const sample = `dana_ledger_write_failures_total{operation="update_order"}`
+	before := metricValue(t, sample)
+	service.updateOrderStatus(ctx, "ORDER-001", repository.StatusSuccess)
+	if got := metricValue(t, sample) - before; got != 1 {
+		t.Fatalf("ledger write failures increased by %v, want 1", got)
+	}
+}
@@ -0,0 +1,66 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/riyanathariq/dana-enterprise/internal/errs"
+	"github.com/riyanathariq/dana-enterprise/internal/money"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
+)
+
+// ListOrdersParams contains filters for listing stored orders
+type ListOrdersParams struct {
+	Status          string     // Optional: Ledger status (e.g. SUCCESS, INITIATED)
+	MerchantID      string     // Optional: Merchant identifier
+	SubMerchantID   string     // Optional: Sub merchant identifier
+	ExternalStoreID string     // Optional: Store identifier
+	ClientID        string     // Optional: API client that created the order
+	CreatedFrom     *time.Time // Optional: Inclusive lower bound of creation time
+	CreatedTo       *time.Time // Optional: Exclusive upper bound of creation time
+	MinAmount       string     // Optional: Inclusive minimum amount (e.g. "10000.00")
+	MaxAmount       string     // Optional: Inclusive maximum amount (e.g. "50000.00")
+	Cursor          string     // Optional: Cursor returned by the previous page
+	Limit           int        // Optional: Page size (default 20, max 100)
+}
+
+// ListOrders returns orders recorded in the ledger without calling DANA
+func (s *Service) ListOrders(ctx context.Context, params ListOrdersParams) ([]*repository.Order, string, error) {
+	filter := repository.OrderFilter{
+		Status:          params.Status,
+		MerchantID:      params.MerchantID,
+		SubMerchantID:   params.SubMerchantID,
+		ExternalStoreID: params.ExternalStoreID,
+		ClientID:        params.ClientID,
+		CreatedFrom:     params.CreatedFrom,
+		CreatedTo:       params.CreatedTo,
+		Cursor:          params.Cursor,
+		Limit:           params.Limit,
+	}
+
+	if params.MinAmount != "" {
+		minAmount, err := money.Parse(params.MinAmount, money.IDR)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: invalid min_amount: %v", errs.ErrInvalidListFilter, err)
+		}
+		minMinor := minAmount.Minor()
+		filter.MinAmount = &minMinor
+	}
+	if params.MaxAmount != "" {
+		maxAmount, err := money.Parse(params.MaxAmount, money.IDR)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: invalid max_amount: %v", errs.ErrInvalidListFilter, err)
+		}
+		maxMinor := maxAmount.Minor()
+		filter.MaxAmount = &maxMinor
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return nil, "", fmt.Errorf("%w: min_amount cannot be greater than max_amount", errs.ErrInvalidListFilter)
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return nil, "", fmt.Errorf("%w: created_from must be before created_to", errs.ErrInvalidListFilter)
+	}
+
+	return s.orders.List(ctx, filter)
+}
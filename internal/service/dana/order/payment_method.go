@@ -0,0 +1,241 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/dana-id/dana-go/payment_gateway/v1"
+	"github.com/riyanathariq/dana-enterprise/internal/errs"
+	"github.com/riyanathariq/dana-enterprise/internal/money"
+)
+
+// TerminalTypes are the terminal types DANA accepts in envInfo
+var TerminalTypes = []string{"APP", "WEB", "WAP", "SYSTEM"}
+
+// amountBandLimits are the upper bounds, in IDR minor units, of the amount bands payment methods are cached for
+// Bands widen with the amount, as options mostly change around limits of a few round amounts
+var amountBandLimits = []int64{
+	10_000_00,     // IDR 10.000
+	50_000_00,     // IDR 50.000
+	100_000_00,    // IDR 100.000
+	500_000_00,    // IDR 500.000
+	1_000_000_00,  // IDR 1.000.000
+	5_000_000_00,  // IDR 5.000.000
+	10_000_000_00, // IDR 10.000.000
+}
+
+// PaymentMethodParams contains the order context payment methods are consulted for
+type PaymentMethodParams struct {
+	MerchantID     string      // Optional: falls back to the default merchant
+	Amount         money.Money // Required: order amount
+	TerminalType   string      // Optional: APP, WEB, WAP or SYSTEM, defaults to WEB
+	ExternalUserID string      // Optional: buyer ID on partner system
+	UserID         string      // Optional: buyer DANA user ID
+}
+
+// PaymentMethod is a pay method and pay option offered for an order
+// Fee and limits are nil when DANA does not send them
+type PaymentMethod struct {
+	PayMethod string
+	PayOption string
+	Fee       *money.Money
+	MinAmount *money.Money
+	MaxAmount *money.Money
+}
+
+// PaymentMethods is the result of a payment method consultation
+type PaymentMethods struct {
+	MerchantID string
+	Amount     money.Money // Amount DANA was consulted with, may differ from the requested amount when cached
+	Methods    []PaymentMethod
+	FetchedAt  time.Time
+	Cached     bool
+}
+
+// GetPaymentMethod consults DANA for the payment methods available to an order
+// Anonymous consultations are cached for PAYMENT_METHOD_CACHE_TTL per merchant, terminal type and amount band;
+// consultations for a buyer are personalized by DANA and never cached
+// Methods whose limits exclude the requested amount are left out, also when served from the cache
+func (s *Service) GetPaymentMethod(ctx context.Context, params PaymentMethodParams) (*PaymentMethods, error) {
+	if params.TerminalType == "" {
+		params.TerminalType = "WEB"
+	}
+	if !slices.Contains(TerminalTypes, params.TerminalType) {
+		return nil, fmt.Errorf("%w: terminal_type must be one of %v, got %q", errs.ErrInvalidPaymentMethodQuery, TerminalTypes, params.TerminalType)
+	}
+	if params.Amount.IsZero() {
+		return nil, fmt.Errorf("%w: amount must be greater than zero", errs.ErrInvalidPaymentMethodQuery)
+	}
+
+	merchant, merchantID, err := s.merchant(params.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	anonymous := params.ExternalUserID == "" && params.UserID == ""
+	key := paymentMethodsKey(merchantID, params.TerminalType, params.Amount)
+	if anonymous {
+		if cached, ok := s.cachedPaymentMethods(key, time.Now()); ok {
+			return withinLimits(cached, params.Amount), nil
+		}
+	}
+
+	buyer := payment_gateway.Buyer{}
+	if params.ExternalUserID != "" {
+		buyer.ExternalUserId = &params.ExternalUserID
+	}
+	if params.UserID != "" {
+		buyer.UserId = &params.UserID
+	}
+
+	result, _, err := merchant.Client.PaymentGatewayAPI.ConsultPay(ctx).
+		ConsultPayRequest(payment_gateway.ConsultPayRequest{
+			MerchantId: merchantID,
+			Amount:     params.Amount.Gateway(),
+			AdditionalInfo: payment_gateway.ConsultPayRequestAdditionalInfo{
+				Buyer: buyer,
+				EnvInfo: payment_gateway.EnvInfo{
+					SourcePlatform:    "IPG",
+					TerminalType:      params.TerminalType,
+					OrderTerminalType: &params.TerminalType,
+				},
+			},
+		}).
+		Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	methods, err := normalizePaymentMethods(result)
+	if err != nil {
+		return nil, err
+	}
+	consultation := &PaymentMethods{
+		MerchantID: merchantID,
+		Amount:     params.Amount,
+		Methods:    methods,
+		FetchedAt:  time.Now(),
+	}
+	if anonymous {
+		s.storePaymentMethods(key, consultation)
+	}
+	return withinLimits(consultation, params.Amount), nil
+}
+
+// paymentInfo is a payment method in the ConsultPay response
+type paymentInfo struct {
+	PayMethod    string                 `json:"payMethod"`
+	PayOption    string                 `json:"payOption"`
+	ChargeAmount *payment_gateway.Money `json:"chargeAmount"`
+	FeeAmount    *payment_gateway.Money `json:"feeAmount"`
+	MinAmount    *payment_gateway.Money `json:"minAmount"`
+	MaxAmount    *payment_gateway.Money `json:"maxAmount"`
+}
+
+// normalizePaymentMethods reads the payment methods of a ConsultPay response
+// Amounts that cannot be parsed are left out rather than failing the whole list
+func normalizePaymentMethods(result *payment_gateway.ConsultPayResponse) ([]PaymentMethod, error) {
+	var response struct {
+		PaymentInfos []paymentInfo `json:"paymentInfos"`
+	}
+	dataBytes, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payment methods: %w", err)
+	}
+	if err := json.Unmarshal(dataBytes, &response); err != nil {
+		return nil, fmt.Errorf("failed to read payment methods: %w", err)
+	}
+
+	methods := make([]PaymentMethod, 0, len(response.PaymentInfos))
+	for _, info := range response.PaymentInfos {
+		if info.PayMethod == "" {
+			continue
+		}
+		fee := info.FeeAmount
+		if fee == nil {
+			fee = info.ChargeAmount
+		}
+		methods = append(methods, PaymentMethod{
+			PayMethod: info.PayMethod,
+			PayOption: info.PayOption,
+			Fee:       optionalMoney(fee),
+			MinAmount: optionalMoney(info.MinAmount),
+			MaxAmount: optionalMoney(info.MaxAmount),
+		})
+	}
+	return methods, nil
+}
+
+// optionalMoney parses an optional amount of a DANA response, returning nil when absent or malformed
+func optionalMoney(m *payment_gateway.Money) *money.Money {
+	if m == nil || m.Value == "" {
+		return nil
+	}
+	amount, err := money.FromGateway(*m)
+	if err != nil {
+		return nil
+	}
+	return &amount
+}
+
+// withinLimits returns a copy of the consultation without the methods whose limits exclude amount
+func withinLimits(consultation *PaymentMethods, amount money.Money) *PaymentMethods {
+	filtered := *consultation
+	filtered.Methods = make([]PaymentMethod, 0, len(consultation.Methods))
+	for _, method := range consultation.Methods {
+		if method.MinAmount != nil {
+			if cmp, err := amount.Cmp(*method.MinAmount); err == nil && cmp < 0 {
+				continue
+			}
+		}
+		if method.MaxAmount != nil {
+			if cmp, err := amount.Cmp(*method.MaxAmount); err == nil && cmp > 0 {
+				continue
+			}
+		}
+		filtered.Methods = append(filtered.Methods, method)
+	}
+	return &filtered
+}
+
+// paymentMethodsKey is the cache key of a consultation: merchant, terminal type, currency and amount band
+func paymentMethodsKey(merchantID, terminalType string, amount money.Money) string {
+	band, _ := slices.BinarySearch(amountBandLimits, amount.Minor())
+	return merchantID + ":" + terminalType + ":" + amount.Currency() + ":" + strconv.Itoa(band)
+}
+
+// cachedPaymentMethods returns an unexpired cached consultation
+func (s *Service) cachedPaymentMethods(key string, now time.Time) (*PaymentMethods, bool) {
+	s.payMethodMu.Lock()
+	defer s.payMethodMu.Unlock()
+
+	consultation, ok := s.payMethods[key]
+	if !ok || !now.Before(consultation.FetchedAt.Add(s.cfg.PayMethods.CacheTTL)) {
+		return nil, false
+	}
+	hit := *consultation
+	hit.Cached = true
+	return &hit, true
+}
+
+// storePaymentMethods caches a consultation and drops expired ones
+func (s *Service) storePaymentMethods(key string, consultation *PaymentMethods) {
+	ttl := s.cfg.PayMethods.CacheTTL
+	if ttl <= 0 {
+		return
+	}
+
+	s.payMethodMu.Lock()
+	defer s.payMethodMu.Unlock()
+
+	for k, cached := range s.payMethods {
+		if !consultation.FetchedAt.Before(cached.FetchedAt.Add(ttl)) {
+			delete(s.payMethods, k)
+		}
+	}
+	s.payMethods[key] = consultation
+}
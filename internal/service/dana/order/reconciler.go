@@ -0,0 +1,202 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	uuid "github.com/google/uuid"
+	"github.com/riyanathariq/dana-enterprise/internal/config"
+	"github.com/riyanathariq/dana-enterprise/internal/logging"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
+	danaSDK "github.com/riyanathariq/dana-enterprise/internal/sdk/dana"
+)
+
+// pendingStatuses are ledger statuses of orders that can still be paid
+var pendingStatuses = []string{
+	repository.StatusInitiated,
+	repository.StatusPaying,
+	repository.StatusPending,
+}
+
+// defaultOrderValidity is the validity of orders recorded without validUpTo, as set by formatValidUpTo
+const defaultOrderValidity = time.Hour
+
+// reconcileTimeout bounds the DANA calls made for one order, which outlive shutdown of the reconciler
+const reconcileTimeout = time.Minute
+
+// Reconciler periodically syncs pending ledger orders with DANA
+// Missed finish-notify webhooks are caught by QueryPayment, and orders past validUpTo are cancelled
+// at DANA and marked EXPIRED. Refunds whose outcome was unknown are re-sent to learn it
+type Reconciler struct {
+	service *Service
+	cfg     config.ReconcilerConfig
+}
+
+// NewReconciler creates a reconciler for the orders of service
+func NewReconciler(service *Service, cfg config.ReconcilerConfig) *Reconciler {
+	return &Reconciler{service: service, cfg: cfg}
+}
+
+// Run reconciles pending orders every interval until ctx is cancelled
+// It returns once the orders being reconciled are done, so callers can wait for it on shutdown
+func (r *Reconciler) Run(ctx context.Context) {
+	slog.Info("order reconciler started", "interval", r.cfg.Interval.String(), "concurrency", r.cfg.Concurrency)
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("order reconciler stopped")
+			return
+		case <-ticker.C:
+			r.ReconcileOnce(ctx)
+		}
+	}
+}
+
+// ReconcileOnce reconciles every pending order created before the last interval
+// Orders are queried with at most Concurrency calls to DANA in flight
+// Cancelling ctx stops picking up orders, orders already picked up are finished
+func (r *Reconciler) ReconcileOnce(ctx context.Context) {
+	runCtx := logging.WithRequestID(ctx, "reconciler-"+uuid.New().String())
+	createdBefore := time.Now().Add(-r.cfg.Interval)
+	r.reconcileRefunds(ctx, runCtx, createdBefore)
+
+	filter := repository.OrderFilter{
+		Statuses:  pendingStatuses,
+		CreatedTo: &createdBefore,
+		Limit:     repository.MaxListLimit,
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, r.cfg.Concurrency)
+	reconciled := 0
+	defer func() {
+		wg.Wait()
+		if reconciled > 0 {
+			slog.InfoContext(runCtx, "pending orders reconciled", "orders", reconciled)
+		}
+	}()
+
+	for ctx.Err() == nil {
+		orders, nextCursor, err := r.service.orders.List(runCtx, filter)
+		if err != nil {
+			slog.ErrorContext(runCtx, "failed to list pending orders", "error", err)
+			return
+		}
+
+		for _, order := range orders {
+			select {
+			case <-ctx.Done():
+				return
+			case slots <- struct{}{}:
+			}
+
+			reconciled++
+			wg.Add(1)
+			go func(order *repository.Order) {
+				defer wg.Done()
+				defer func() { <-slots }()
+
+				orderCtx, cancel := context.WithTimeout(context.WithoutCancel(runCtx), reconcileTimeout)
+				defer cancel()
+				r.reconcileOrder(orderCtx, order)
+			}(order)
+		}
+
+		if nextCursor == "" {
+			return
+		}
+		filter.Cursor = nextCursor
+	}
+}
+
+// reconcileOrder syncs one pending order with DANA and expires it once past validUpTo
+func (r *Reconciler) reconcileOrder(ctx context.Context, order *repository.Order) {
+	ref := order.PartnerReferenceNo
+	expired := time.Now().After(orderDeadline(order))
+
+	// GetOrder updates the ledger with the DANA status
+	if _, err := r.service.GetOrder(ctx, ref); err != nil {
+		if expired && isDanaCode(err, "404", "01") {
+			// Never reached DANA, e.g. the create call failed after the order was recorded
+			r.service.updateOrderStatus(ctx, ref, repository.StatusExpired)
+			return
+		}
+		if !errors.Is(err, danaSDK.ErrCircuitOpen) {
+			slog.WarnContext(ctx, "failed to query pending order", "partner_reference_no", ref, "error", err)
+		}
+		return
+	}
+	if !expired {
+		return
+	}
+
+	current, err := r.service.orders.Get(ctx, ref)
+	if err != nil || !slices.Contains(pendingStatuses, current.Status) {
+		return
+	}
+
+	// Cancel at DANA so the order can no longer be paid, then record it as expired rather than cancelled
+	reason := "Order expired"
+	_, err = r.service.cancelOrder(ctx, CancelOrderRequestParams{
+		PartnerReferenceNo: ref,
+		MerchantID:         order.MerchantID,
+		Reason:             &reason,
+	})
+	if err != nil && !isDanaCode(err, "403", "00") {
+		slog.WarnContext(ctx, "failed to cancel expired order", "partner_reference_no", ref, "error", err)
+		return
+	}
+	r.service.updateOrderStatus(ctx, ref, repository.StatusExpired)
+	slog.InfoContext(ctx, "order expired", "partner_reference_no", ref, "valid_up_to", order.ValidUpTo)
+}
+
+// reconcileRefunds resolves pending refunds created before createdBefore, one at a time
+// Refunds stay pending only when a refund call failed without a clear answer from DANA, so there are few of them
+func (r *Reconciler) reconcileRefunds(ctx, runCtx context.Context, createdBefore time.Time) {
+	refunds, err := r.service.refunds.ListPendingRefunds(runCtx, createdBefore, repository.MaxListLimit)
+	if err != nil {
+		slog.ErrorContext(runCtx, "failed to list pending refunds", "error", err)
+		return
+	}
+
+	for _, refund := range refunds {
+		if ctx.Err() != nil {
+			return
+		}
+		refundCtx, cancel := context.WithTimeout(context.WithoutCancel(runCtx), reconcileTimeout)
+		err := r.service.resolveRefund(refundCtx, refund)
+		cancel()
+
+		switch {
+		case err == nil:
+			slog.InfoContext(runCtx, "pending refund resolved", "partner_refund_no", refund.PartnerRefundNo, "refund_no", refund.RefundNo)
+		case refundNotMade(err):
+			slog.InfoContext(runCtx, "pending refund rejected by DANA", "partner_refund_no", refund.PartnerRefundNo, "error", err)
+		case errors.Is(err, danaSDK.ErrCircuitOpen):
+			// Retried on the next run once the breaker closes
+		default:
+			slog.WarnContext(runCtx, "failed to resolve pending refund, left pending", "partner_refund_no", refund.PartnerRefundNo, "error", err)
+		}
+	}
+}
+
+// orderDeadline returns when an order stops being payable
+func orderDeadline(order *repository.Order) time.Time {
+	if deadline, err := time.Parse(time.RFC3339, order.ValidUpTo); err == nil {
+		return deadline
+	}
+	return order.CreatedAt.Add(defaultOrderValidity)
+}
+
+// isDanaCode reports whether err is a DANA error with the given SNAP HTTP status and case code
+func isDanaCode(err error, httpStatus, caseCode string) bool {
+	danaErr, ok := danaSDK.AsDanaError(err)
+	return ok && danaErr.CaseCode() == caseCode && danaErr.ResponseCode[:3] == httpStatus
+}
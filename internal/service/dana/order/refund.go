@@ -0,0 +1,251 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/dana-id/dana-go/payment_gateway/v1"
+	"github.com/riyanathariq/dana-enterprise/internal/errs"
+	"github.com/riyanathariq/dana-enterprise/internal/metrics"
+	"github.com/riyanathariq/dana-enterprise/internal/money"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
+	danaSDK "github.com/riyanathariq/dana-enterprise/internal/sdk/dana"
+)
+
+// RefundOrderRequestParams contains parameters for refunding an order
+type RefundOrderRequestParams struct {
+	PartnerReferenceNo string                // Required: Original transaction identifier on partner system
+	PartnerRefundNo    string                // Required: Refund identifier on partner system
+	MerchantID         string                // Optional: Merchant identifier, falls back to the ledger or default merchant
+	Amount             payment_gateway.Money // Required: Amount to refund
+	Reason             string                // Optional: Refund reason
+}
+
+// queryOrderAmount returns the original amount of an order from the ledger, falling back to DANA
+func (s *Service) queryOrderAmount(ctx context.Context, partnerReferenceNo string) (money.Money, error) {
+	if order, err := s.orders.Get(ctx, partnerReferenceNo); err == nil {
+		return money.Parse(order.AmountValue, order.AmountCurrency)
+	}
+
+	result, err := s.GetOrder(ctx, partnerReferenceNo)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	// QueryPayment response carries the order amount as amount or transAmount
+	var amounts struct {
+		Amount      *payment_gateway.Money `json:"amount"`
+		TransAmount *payment_gateway.Money `json:"transAmount"`
+	}
+	dataBytes, err := json.Marshal(result)
+	if err != nil {
+		return money.Money{}, fmt.Errorf("failed to read order amount: %w", err)
+	}
+	if err := json.Unmarshal(dataBytes, &amounts); err != nil {
+		return money.Money{}, fmt.Errorf("failed to read order amount: %w", err)
+	}
+
+	switch {
+	case amounts.Amount != nil && amounts.Amount.Value != "":
+		return money.FromGateway(*amounts.Amount)
+	case amounts.TransAmount != nil && amounts.TransAmount.Value != "":
+		return money.FromGateway(*amounts.TransAmount)
+	}
+	return money.Money{}, fmt.Errorf("order %s has no amount", partnerReferenceNo)
+}
+
+// reserveRefund records a pending refund in the ledger if it fits into the refundable amount of the order
+func (s *Service) reserveRefund(ctx context.Context, refund *repository.Refund, orderAmount money.Money) error {
+	refunded, err := s.refunds.ReserveRefund(ctx, refund, orderAmount.Minor())
+	switch {
+	case errors.Is(err, repository.ErrDuplicate):
+		return errs.ErrDuplicateRefund
+	case errors.Is(err, repository.ErrRefundLimitExceeded):
+		refundedAmount, _ := money.New(refunded, orderAmount.Currency())
+		return fmt.Errorf("%w: order amount %s, already refunded %s, requested %s",
+			errs.ErrRefundExceedsAmount, orderAmount, refundedAmount, refund.AmountValue)
+	case err != nil:
+		return fmt.Errorf("failed to reserve refund: %w", err)
+	}
+	return nil
+}
+
+// RefundOrder issues a full or partial refund using DANA RefundOrder API
+func (s *Service) RefundOrder(ctx context.Context, params RefundOrderRequestParams) (*repository.Refund, error) {
+	// Validate required fields before the ledger is consulted for the merchant
+	if params.PartnerReferenceNo == "" {
+		return nil, fmt.Errorf("partnerReferenceNo is required")
+	}
+	if params.PartnerRefundNo == "" {
+		return nil, fmt.Errorf("partnerRefundNo is required")
+	}
+	if params.Amount.Value == "" || params.Amount.Currency == "" {
+		return nil, fmt.Errorf("refundAmount is required")
+	}
+
+	// Use merchant ID from params, the ledger or fallback to the default merchant
+	merchant, merchantID, err := s.merchantForOrder(ctx, params.PartnerReferenceNo, params.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	if merchantID == "" {
+		return nil, fmt.Errorf("merchantId is required")
+	}
+
+	// Normalize the amount to the exact format DANA requires, e.g. "10000.00" for IDR
+	amount, err := money.FromGateway(params.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("refundAmount: %w", err)
+	}
+	if amount.IsZero() {
+		return nil, fmt.Errorf("refundAmount: %w: must be greater than zero", money.ErrInvalidAmount)
+	}
+	formattedAmount := amount.Gateway()
+
+	orderAmount, err := s.queryOrderAmount(ctx, params.PartnerReferenceNo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query original order: %w", err)
+	}
+	if orderAmount.Currency() != amount.Currency() {
+		return nil, fmt.Errorf("%w: refund currency %s does not match order currency %s", money.ErrCurrencyMismatch, amount.Currency(), orderAmount.Currency())
+	}
+
+	refund := &repository.Refund{
+		PartnerRefundNo:    params.PartnerRefundNo,
+		PartnerReferenceNo: params.PartnerReferenceNo,
+		MerchantID:         merchantID,
+		AmountValue:        formattedAmount.Value,
+		AmountCurrency:     formattedAmount.Currency,
+		AmountMinor:        amount.Minor(),
+		Reason:             params.Reason,
+		Status:             repository.RefundStatusPending,
+	}
+	if err := s.reserveRefund(ctx, refund, orderAmount); err != nil {
+		return nil, err
+	}
+
+	if err := s.executeRefund(ctx, merchant, refund); err != nil {
+		if refundRejected(err) {
+			// Release the reservation so the amount can be refunded again
+			s.releaseRefund(ctx, refund.PartnerRefundNo)
+			return nil, err
+		}
+		// DANA may have refunded, so the refund is reported as pending rather than failed
+		slog.WarnContext(ctx, "refund outcome unknown, left pending for the reconciler",
+			"partner_refund_no", refund.PartnerRefundNo, "error", err)
+	}
+
+	return refund, nil
+}
+
+// executeRefund sends a reserved refund to DANA and records a successful refund in the ledger
+// On error the refund is left PENDING, still counting towards the refunded amount; the caller decides whether
+// the error proves that DANA did not refund and the reservation can be released
+func (s *Service) executeRefund(ctx context.Context, merchant *danaSDK.Merchant, refund *repository.Refund) error {
+	var reason *string
+	if refund.Reason != "" {
+		reason = &refund.Reason
+	}
+
+	result, _, err := merchant.Client.PaymentGatewayAPI.RefundOrder(ctx).
+		RefundOrderRequest(payment_gateway.RefundOrderRequest{
+			OriginalPartnerReferenceNo: refund.PartnerReferenceNo,
+			PartnerRefundNo:            refund.PartnerRefundNo,
+			MerchantId:                 refund.MerchantID,
+			RefundAmount:               payment_gateway.Money{Value: refund.AmountValue, Currency: refund.AmountCurrency},
+			Reason:                     reason,
+		}).
+		Execute()
+	if err != nil {
+		return err
+	}
+
+	// Extract DANA refund number and time from response
+	var refundInfo struct {
+		RefundNo   string `json:"refundNo"`
+		RefundTime string `json:"refundTime"`
+	}
+	if dataBytes, err := json.Marshal(result); err == nil {
+		_ = json.Unmarshal(dataBytes, &refundInfo)
+	}
+
+	refund.RefundNo = refundInfo.RefundNo
+	refund.RefundTime = refundInfo.RefundTime
+	refund.Status = repository.RefundStatusSuccess
+	// DANA has refunded at this point, so a failed write still reports the refund as successful
+	if err := s.refunds.UpdateRefund(ctx, refund); err != nil {
+		metrics.LedgerWriteFailed(metrics.LedgerUpdateRefund)
+		slog.WarnContext(ctx, "failed to record refund", "partner_refund_no", refund.PartnerRefundNo, "error", err)
+	}
+	return nil
+}
+
+// releaseRefund deletes a refund reservation DANA did not make
+func (s *Service) releaseRefund(ctx context.Context, partnerRefundNo string) {
+	if err := s.refunds.DeleteRefund(ctx, partnerRefundNo); err != nil {
+		metrics.LedgerWriteFailed(metrics.LedgerDeleteRefund)
+		slog.WarnContext(ctx, "failed to release refund", "partner_refund_no", partnerRefundNo, "error", err)
+	}
+}
+
+// resolveRefund re-sends a pending refund to learn its outcome
+// An earlier attempt may already have refunded, so a rejected resend does not prove the refund failed: DANA
+// refuses a partnerRefundNo it has seen, and a second refund of the same amount may exceed the order. Only
+// errors in refundNotMade release the reservation; any other error leaves the refund PENDING
+func (s *Service) resolveRefund(ctx context.Context, refund *repository.Refund) error {
+	merchant, _, err := s.merchant(refund.MerchantID)
+	if err != nil {
+		return err
+	}
+	err = s.executeRefund(ctx, merchant, refund)
+	if err != nil && refundNotMade(err) {
+		s.releaseRefund(ctx, refund.PartnerRefundNo)
+	}
+	return err
+}
+
+// refundNotMade reports whether DANA answered a refund with a case code that no earlier attempt of the same
+// request can have passed: a malformed request (400) or an order DANA does not know (404xx01)
+func refundNotMade(err error) bool {
+	danaErr, ok := danaSDK.AsDanaError(err)
+	if !ok {
+		return false
+	}
+	switch danaErr.HTTPStatus {
+	case http.StatusBadRequest:
+		return true
+	case http.StatusNotFound:
+		return danaErr.CaseCode() == "01"
+	}
+	return false
+}
+
+// refundRejected reports whether the first attempt of a refund definitely did not refund: the request was never
+// sent, or DANA answered with a 4xx business error. Timeouts, 5xx responses and transport errors leave the
+// outcome unknown
+func refundRejected(err error) bool {
+	if errors.Is(err, danaSDK.ErrCircuitOpen) {
+		return true
+	}
+	danaErr, ok := danaSDK.AsDanaError(err)
+	if !ok {
+		return false
+	}
+	return danaErr.HTTPStatus >= 400 && danaErr.HTTPStatus < 500 && danaErr.HTTPStatus != http.StatusRequestTimeout
+}
+
+// GetRefund returns a refund by partner refund number
+func (s *Service) GetRefund(ctx context.Context, partnerRefundNo string) (*repository.Refund, error) {
+	refund, err := s.refunds.GetRefund(ctx, partnerRefundNo)
+	if errors.Is(err, repository.ErrRefundNotFound) {
+		return nil, errs.ErrRefundNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refund: %w", err)
+	}
+	return refund, nil
+}
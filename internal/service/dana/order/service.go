@@ -4,54 +4,36 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dana-id/dana-go/payment_gateway/v1"
+	"github.com/riyanathariq/dana-enterprise/internal/config"
+	"github.com/riyanathariq/dana-enterprise/internal/errs"
+	"github.com/riyanathariq/dana-enterprise/internal/money"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
 	danaSDK "github.com/riyanathariq/dana-enterprise/internal/sdk/dana"
-	"github.com/riyanathariq/dana-enterprise/package/dana"
 )
 
-type Service struct{}
+type Service struct {
+	cfg       *config.Config
+	merchants *danaSDK.Registry
+	orders    repository.OrderRepository
+	refunds   repository.RefundRepository
 
-func NewService() *Service {
-	return &Service{}
+	payMethodMu sync.Mutex
+	payMethods  map[string]*PaymentMethods // keyed by paymentMethodsKey
 }
 
-// formatAmountValue ensures amount value has exactly 2 decimal places for IDR currency
-// DANA API requires format: "100000.00" not "100000" or "100000.0"
-func formatAmountValue(value string) string {
-	// If value is empty, return as is
-	if value == "" {
-		return value
-	}
-
-	// Remove any whitespace
-	value = strings.TrimSpace(value)
-
-	// Check if value already has decimal point
-	if strings.Contains(value, ".") {
-		parts := strings.Split(value, ".")
-		intPart := parts[0]
-		decimalPart := parts[1]
-
-		// Ensure decimal part has exactly 2 digits
-		if len(decimalPart) == 0 {
-			decimalPart = "00"
-		} else if len(decimalPart) == 1 {
-			decimalPart = decimalPart + "0"
-		} else if len(decimalPart) > 2 {
-			// Truncate to 2 decimal places
-			decimalPart = decimalPart[:2]
-		}
-
-		return intPart + "." + decimalPart
+func NewService(cfg *config.Config, merchants *danaSDK.Registry, orders repository.OrderRepository, refunds repository.RefundRepository) *Service {
+	return &Service{
+		cfg:        cfg,
+		merchants:  merchants,
+		orders:     orders,
+		refunds:    refunds,
+		payMethods: make(map[string]*PaymentMethods),
 	}
-
-	// If no decimal point, add ".00"
-	return value + ".00"
 }
 
 // CreateOrderRequestParams contains parameters for creating an order
@@ -102,6 +84,36 @@ func normalizeUrlParams(params []payment_gateway.UrlParam) ([]payment_gateway.Ur
 	return normalizedUrlParams, nil
 }
 
+// merchant resolves the DANA clients and merchant ID for a request, falling back to the default merchant
+func (s *Service) merchant(merchantID string) (*danaSDK.Merchant, string, error) {
+	merchant, err := s.merchants.Get(merchantID)
+	if err != nil {
+		return nil, "", err
+	}
+	if merchantID == "" {
+		merchantID = merchant.ID
+	}
+	return merchant, merchantID, nil
+}
+
+// merchantForOrder resolves the merchant of an existing order, using the ledger when merchantID is empty
+func (s *Service) merchantForOrder(ctx context.Context, partnerReferenceNo, merchantID string) (*danaSDK.Merchant, string, error) {
+	if merchantID == "" {
+		if order, err := s.orders.Get(ctx, partnerReferenceNo); err == nil {
+			merchantID = order.MerchantID
+		}
+	}
+	return s.merchant(merchantID)
+}
+
+// orderTitle returns the configured order title, defaulting to "Order {partnerReferenceNo}"
+func (s *Service) orderTitle(partnerReferenceNo string) string {
+	if s.cfg.Order.Title != "" {
+		return s.cfg.Order.Title
+	}
+	return "Order " + partnerReferenceNo
+}
+
 // formatValidUpTo formats validUpTo in Jakarta timezone
 func formatValidUpTo(validUpTo *string) *string {
 	if validUpTo != nil && *validUpTo != "" {
@@ -115,11 +127,11 @@ func formatValidUpTo(validUpTo *string) *string {
 // CreateOrderHostedCheckout creates a new order using Hosted Checkout (Redirect)
 // User will be redirected to DANA payment page to select payment method
 // Uses raw HTTP request instead of SDK
-func (s *Service) CreateOrderHostedCheckout(ctx context.Context, params CreateOrderRequestParams) (*payment_gateway.CreateOrderResponse, error) {
-	// Use merchant ID from params or fallback to env
-	merchantID := params.MerchantID
-	if merchantID == "" {
-		merchantID = os.Getenv("DANA_MERCHANT_ID")
+func (s *Service) CreateOrderHostedCheckout(ctx context.Context, params CreateOrderRequestParams) (*CreatedOrder, error) {
+	// Use merchant ID from params or fallback to the default merchant
+	merchant, merchantID, err := s.merchant(params.MerchantID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate required fields
@@ -133,11 +145,12 @@ func (s *Service) CreateOrderHostedCheckout(ctx context.Context, params CreateOr
 		return nil, fmt.Errorf("urlParams is required and cannot be empty")
 	}
 
-	// Format amount value to ensure 2 decimal places for IDR
-	formattedAmount := params.Amount
-	if params.Amount.Currency == "IDR" {
-		formattedAmount.Value = formatAmountValue(params.Amount.Value)
+	// Normalize the amount to the exact format DANA requires, e.g. "10000.00" for IDR
+	amount, err := money.FromGateway(params.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("amount: %w", err)
 	}
+	formattedAmount := amount.Gateway()
 
 	validUpTo := formatValidUpTo(params.ValidUpTo)
 	normalizedUrlParams, err := normalizeUrlParams(params.UrlParams)
@@ -146,19 +159,12 @@ func (s *Service) CreateOrderHostedCheckout(ctx context.Context, params CreateOr
 	}
 
 	// AdditionalInfo for Hosted Checkout (redirect)
-	// Use MCC code from environment or default to a common one
 	// Common MCC codes: 5411 (Grocery), 5999 (Miscellaneous), 5812 (Restaurants)
-	mccCode := os.Getenv("DANA_MCC")
-	if mccCode == "" {
-		mccCode = "5999" // Default to Miscellaneous if not set
-	}
+	mccCode := s.cfg.Order.MCC
 	webTerminalType := "WEB"
 
 	// Order object - required for hosted checkout redirect scenario
-	orderTitle := os.Getenv("DANA_ORDER_TITLE")
-	if orderTitle == "" {
-		orderTitle = "Order " + params.PartnerReferenceNo // Default order title
-	}
+	orderTitle := s.orderTitle(params.PartnerReferenceNo)
 	scenario := "REDIRECT" // Required for hosted checkout redirect
 	orderObj := &payment_gateway.OrderRedirectObject{
 		OrderTitle: orderTitle,
@@ -190,45 +196,39 @@ func (s *Service) CreateOrderHostedCheckout(ctx context.Context, params CreateOr
 	}
 
 	// Call raw HTTP request for hosted checkout
-	rawResponse, err := danaSDK.CreateOrderHostedRaw(ctx, rawParams)
+	rawResponse, err := merchant.Transport.CreateOrderHostedRaw(ctx, rawParams)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create order (raw HTTP): %w", err)
 	}
 
-	// Convert raw response to SDK response format
-	// The raw response structure matches DANA API response
-	order := &payment_gateway.CreateOrderResponse{
-		ResponseCode:       rawResponse.ResponseCode,
-		ResponseMessage:    rawResponse.ResponseMessage,
-		PartnerReferenceNo: rawResponse.PartnerReferenceNo,
-	}
-
-	// If response has data, try to unmarshal it to the full response structure
-	if rawResponse.Data != nil {
-		if dataBytes, err := json.Marshal(rawResponse.Data); err == nil {
-			// Try to unmarshal into the full CreateOrderResponse structure
-			var fullResponse payment_gateway.CreateOrderResponse
-			if err := json.Unmarshal(dataBytes, &fullResponse); err == nil {
-				order = &fullResponse
-			} else {
-				// If unmarshal fails, at least we have the basic fields
-				fmt.Printf("Warning: Could not unmarshal response data: %v\n", err)
-			}
-		}
+	created := &CreatedOrder{
+		PartnerReferenceNo: params.PartnerReferenceNo,
+		ReferenceNo:        rawResponse.ReferenceNo,
+		MerchantID:         merchantID,
+		CheckoutType:       repository.CheckoutTypeHosted,
+		Amount:             amount,
+		WebRedirectURL:     rawResponse.WebRedirectUrl,
+		ValidUpTo:          *validUpTo,
+	}
+	// The order exists in DANA at this point, so it is recorded even if the payment code cannot be read
+	created.PaymentCode, err = readPaymentCode(rawResponse.AdditionalInfo)
+	s.recordOrder(ctx, params, created)
+	if err != nil {
+		return nil, fmt.Errorf("order %s created: %w", params.PartnerReferenceNo, err)
 	}
 
-	return order, nil
+	return created, nil
 }
 
 // CreateOrderCustomCheckout creates a new order using Custom Checkout (Host-to-Host)
 // Requires PayOptionDetails to specify payment method directly
 // Uses raw HTTP request instead of SDK
-func (s *Service) CreateOrderCustomCheckout(ctx context.Context, params CreateOrderRequestParams) (*payment_gateway.CreateOrderResponse, error) {
+func (s *Service) CreateOrderCustomCheckout(ctx context.Context, params CreateOrderRequestParams) (*CreatedOrder, error) {
 
-	// Use merchant ID from params or fallback to env
-	merchantID := params.MerchantID
-	if merchantID == "" {
-		merchantID = os.Getenv("DANA_MERCHANT_ID")
+	// Use merchant ID from params or fallback to the default merchant
+	merchant, merchantID, err := s.merchant(params.MerchantID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate required fields
@@ -245,45 +245,40 @@ func (s *Service) CreateOrderCustomCheckout(ctx context.Context, params CreateOr
 		return nil, fmt.Errorf("urlParams is required and cannot be empty")
 	}
 
-	// Format amount value to ensure 2 decimal places for IDR
-	formattedAmount := params.Amount
-	if params.Amount.Currency == "IDR" {
-		formattedAmount.Value = formatAmountValue(params.Amount.Value)
+	// Normalize the amount to the exact format DANA requires, e.g. "10000.00" for IDR
+	amount, err := money.FromGateway(params.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("amount: %w", err)
 	}
+	formattedAmount := amount.Gateway()
 
-	// Format PayOptionDetails amounts
+	// Normalize PayOptionDetails amounts, whose transAmounts must add up to the order amount
 	formattedPayOptionDetails := make([]payment_gateway.PayOptionDetail, len(params.PayOptionDetails))
-	totalTransAmount := 0.0
+	totalTransAmount, _ := money.New(0, amount.Currency())
 	for i, pod := range params.PayOptionDetails {
 		formattedPayOptionDetails[i] = pod
-		if pod.TransAmount.Currency == "IDR" {
-			formattedPayOptionDetails[i].TransAmount.Value = formatAmountValue(pod.TransAmount.Value)
-			// Track total for validation
-			if val, err := strconv.ParseFloat(formattedPayOptionDetails[i].TransAmount.Value, 64); err == nil {
-				totalTransAmount += val
-			}
+
+		transAmount, err := money.FromGateway(pod.TransAmount)
+		if err != nil {
+			return nil, fmt.Errorf("payOptionDetails[%d].transAmount: %w", i, err)
 		}
-		if pod.FeeAmount != nil && pod.FeeAmount.Currency == "IDR" {
-			feeValue := formatAmountValue(pod.FeeAmount.Value)
-			formattedFeeAmount := &payment_gateway.Money{
-				Value:    feeValue,
-				Currency: pod.FeeAmount.Currency,
-			}
-			formattedPayOptionDetails[i].FeeAmount = formattedFeeAmount
+		formattedPayOptionDetails[i].TransAmount = transAmount.Gateway()
+		if totalTransAmount, err = totalTransAmount.Add(transAmount); err != nil {
+			return nil, fmt.Errorf("payOptionDetails[%d].transAmount: %w", i, err)
 		}
-	}
 
-	// Validate: total transAmount should match main amount (for IDR)
-	if formattedAmount.Currency == "IDR" {
-		if mainAmount, err := strconv.ParseFloat(formattedAmount.Value, 64); err == nil {
-			if totalTransAmount > 0 && mainAmount != totalTransAmount {
-				// Warning: total transAmount doesn't match main amount, but continue anyway
-				if debug, _ := strconv.ParseBool(os.Getenv("DANA_DEBUG")); debug {
-					fmt.Printf("WARNING: Total transAmount (%.2f) doesn't match main amount (%.2f)\n", totalTransAmount, mainAmount)
-				}
+		if pod.FeeAmount != nil {
+			feeAmount, err := money.FromGateway(*pod.FeeAmount)
+			if err != nil {
+				return nil, fmt.Errorf("payOptionDetails[%d].feeAmount: %w", i, err)
 			}
+			formattedFeeAmount := feeAmount.Gateway()
+			formattedPayOptionDetails[i].FeeAmount = &formattedFeeAmount
 		}
 	}
+	if cmp, _ := totalTransAmount.Cmp(amount); cmp != 0 {
+		return nil, fmt.Errorf("%w: transAmounts add up to %s, amount is %s", errs.ErrAmountMismatch, totalTransAmount, amount)
+	}
 
 	validUpTo := formatValidUpTo(params.ValidUpTo)
 	normalizedUrlParams, err := normalizeUrlParams(params.UrlParams)
@@ -292,38 +287,30 @@ func (s *Service) CreateOrderCustomCheckout(ctx context.Context, params CreateOr
 	}
 
 	// AdditionalInfo for Custom Checkout (Host-to-Host)
-	mccCode := os.Getenv("DANA_MCC")
-	if mccCode == "" {
-		mccCode = "5999" // Default to Miscellaneous if not set
-	}
+	mccCode := s.cfg.Order.MCC
 	webTerminalType := "WEB"
 
 	// Order object - required for custom checkout
 	// Based on DANA documentation, need orderTitle, scenario, merchantTransType, and buyer (REQUIRED)
-	orderTitle := os.Getenv("DANA_ORDER_TITLE")
-	if orderTitle == "" {
-		orderTitle = "Order " + params.PartnerReferenceNo
-	}
+	orderTitle := s.orderTitle(params.PartnerReferenceNo)
 	scenario := "API" // Required for custom checkout (host-to-host)
-	merchantTransType := os.Getenv("DANA_MERCHANT_TRANS_TYPE")
-	if merchantTransType == "" {
-		merchantTransType = "SALE" // Default to SALE if not set
-	}
+	merchantTransType := s.cfg.Order.MerchantTransType
 
 	// Buyer is REQUIRED for custom checkout
 	// All fields are optional, but buyer object itself is required
+	buyer := s.cfg.Order.Buyer
 	buyerObj := &payment_gateway.Buyer{}
-	if externalUserId := os.Getenv("DANA_BUYER_EXTERNAL_USER_ID"); externalUserId != "" {
-		buyerObj.ExternalUserId = &externalUserId
+	if buyer.ExternalUserID != "" {
+		buyerObj.ExternalUserId = &buyer.ExternalUserID
 	}
-	if userId := os.Getenv("DANA_BUYER_USER_ID"); userId != "" {
-		buyerObj.UserId = &userId
+	if buyer.UserID != "" {
+		buyerObj.UserId = &buyer.UserID
 	}
-	if nickname := os.Getenv("DANA_BUYER_NICKNAME"); nickname != "" {
-		buyerObj.Nickname = &nickname
+	if buyer.Nickname != "" {
+		buyerObj.Nickname = &buyer.Nickname
 	}
-	if externalUserType := os.Getenv("DANA_BUYER_EXTERNAL_USER_TYPE"); externalUserType != "" {
-		buyerObj.ExternalUserType = &externalUserType
+	if buyer.ExternalUserType != "" {
+		buyerObj.ExternalUserType = &buyer.ExternalUserType
 	}
 
 	orderObj := &payment_gateway.OrderApiObject{
@@ -342,20 +329,21 @@ func (s *Service) CreateOrderCustomCheckout(ctx context.Context, params CreateOr
 	}
 
 	// Optional: Add more envInfo fields if provided
-	if clientIp := os.Getenv("DANA_CLIENT_IP"); clientIp != "" {
-		envInfo.ClientIp = &clientIp
+	configured := s.cfg.Order.EnvInfo
+	if configured.ClientIP != "" {
+		envInfo.ClientIp = &configured.ClientIP
 	}
-	if sessionId := os.Getenv("DANA_SESSION_ID"); sessionId != "" {
-		envInfo.SessionId = &sessionId
+	if configured.SessionID != "" {
+		envInfo.SessionId = &configured.SessionID
 	}
-	if tokenId := os.Getenv("DANA_TOKEN_ID"); tokenId != "" {
-		envInfo.TokenId = &tokenId
+	if configured.TokenID != "" {
+		envInfo.TokenId = &configured.TokenID
 	}
-	if osType := os.Getenv("DANA_OS_TYPE"); osType != "" {
-		envInfo.OsType = &osType
+	if configured.OSType != "" {
+		envInfo.OsType = &configured.OSType
 	}
-	if websiteLanguage := os.Getenv("DANA_WEBSITE_LANGUAGE"); websiteLanguage != "" {
-		envInfo.WebsiteLanguage = &websiteLanguage
+	if configured.WebsiteLanguage != "" {
+		envInfo.WebsiteLanguage = &configured.WebsiteLanguage
 	}
 
 	additionalInfo := &payment_gateway.CreateOrderByApiAdditionalInfo{
@@ -380,82 +368,131 @@ func (s *Service) CreateOrderCustomCheckout(ctx context.Context, params CreateOr
 	}
 
 	// Call raw HTTP request
-	rawResponse, err := danaSDK.CreateOrderRaw(ctx, rawParams)
+	rawResponse, err := merchant.Transport.CreateOrderRaw(ctx, rawParams)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create order (raw HTTP): %w", err)
 	}
 
-	// Convert raw response to SDK response format
-	// The raw response structure matches DANA API response
-	order := &payment_gateway.CreateOrderResponse{
-		ResponseCode:       rawResponse.ResponseCode,
-		ResponseMessage:    rawResponse.ResponseMessage,
-		PartnerReferenceNo: rawResponse.PartnerReferenceNo,
-	}
-
-	// If response has data, try to unmarshal it to the full response structure
-	if rawResponse.Data != nil {
-		if dataBytes, err := json.Marshal(rawResponse.Data); err == nil {
-			// Try to unmarshal into the full CreateOrderResponse structure
-			var fullResponse payment_gateway.CreateOrderResponse
-			if err := json.Unmarshal(dataBytes, &fullResponse); err == nil {
-				order = &fullResponse
-			} else {
-				// If unmarshal fails, at least we have the basic fields
-				fmt.Printf("Warning: Could not unmarshal response data: %v\n", err)
-			}
-		}
+	created := &CreatedOrder{
+		PartnerReferenceNo: params.PartnerReferenceNo,
+		ReferenceNo:        rawResponse.ReferenceNo,
+		MerchantID:         merchantID,
+		CheckoutType:       repository.CheckoutTypeCustom,
+		Amount:             amount,
+		PayOptionDetails:   formattedPayOptionDetails,
+		WebRedirectURL:     rawResponse.WebRedirectUrl,
+		ValidUpTo:          *validUpTo,
+	}
+	// The order exists in DANA at this point, so it is recorded even if the payment code cannot be read
+	created.PaymentCode, err = readPaymentCode(rawResponse.AdditionalInfo)
+	s.recordOrder(ctx, params, created)
+	if err != nil {
+		return nil, fmt.Errorf("order %s created: %w", params.PartnerReferenceNo, err)
+	}
+
+	return created, nil
+}
+
+func (s *Service) GetOrder(ctx context.Context, partnerReferenceNo string) (*payment_gateway.QueryPaymentResponse, error) {
+	merchant, merchantID, err := s.merchantForOrder(ctx, partnerReferenceNo, "")
+	if err != nil {
+		return nil, err
+	}
+
+	order, _, err := merchant.Client.PaymentGatewayAPI.QueryPayment(ctx).
+		QueryPaymentRequest(payment_gateway.QueryPaymentRequest{
+			OriginalPartnerReferenceNo: &partnerReferenceNo,
+			MerchantId:                 merchantID,
+			ServiceCode:                "54",
+		}).
+		Execute()
+	if err != nil {
+		return nil, err
 	}
 
+	// Sync ledger status with DANA latestTransactionStatus
+	var status struct {
+		LatestTransactionStatus string `json:"latestTransactionStatus"`
+	}
+	if dataBytes, err := json.Marshal(order); err == nil {
+		_ = json.Unmarshal(dataBytes, &status)
+	}
+	s.updateOrderStatus(ctx, partnerReferenceNo, statusFromDANA(status.LatestTransactionStatus))
+
 	return order, nil
 }
 
-func (s *Service) GetPaymentMethod(ctx context.Context) (*payment_gateway.ConsultPayResponse, error) {
-	danaClient := dana.InitData()
+// HandleFinishNotify processes a verified finish-notify payload sent by DANA
+func (s *Service) HandleFinishNotify(ctx context.Context, notify *danaSDK.FinishNotifyRequest) error {
+	if notify == nil {
+		return fmt.Errorf("finish notify payload is required")
+	}
+	if notify.OriginalPartnerReferenceNo == "" {
+		return fmt.Errorf("originalPartnerReferenceNo is required")
+	}
+	if notify.LatestTransactionStatus == "" {
+		return fmt.Errorf("latestTransactionStatus is required")
+	}
 
-	_ = os.Getenv("DANA_MERCHANT_ID")
+	s.updateOrderStatus(ctx, notify.OriginalPartnerReferenceNo, statusFromDANA(notify.LatestTransactionStatus))
+	return nil
+}
 
-	webTerminalType := "WEB"
+// CancelOrderRequestParams contains parameters for cancelling an order
+type CancelOrderRequestParams struct {
+	PartnerReferenceNo string                 // Required: Original transaction identifier on partner system
+	MerchantID         string                 // Optional: Merchant identifier, falls back to the ledger or default merchant
+	Reason             *string                // Optional: Cancellation reason
+	Amount             *payment_gateway.Money // Optional: Amount to cancel
+}
 
-	// Format amount value to ensure 2 decimal places
-	amountValue := formatAmountValue("100000")
-	paymentMethod, _, err := danaClient.PaymentGatewayAPI.ConsultPay(ctx).
-		ConsultPayRequest(payment_gateway.ConsultPayRequest{
-			MerchantId: "",
-			Amount: payment_gateway.Money{
-				Value:    amountValue,
-				Currency: "IDR",
-			},
-			AdditionalInfo: payment_gateway.ConsultPayRequestAdditionalInfo{
-				Buyer: payment_gateway.Buyer{},
-				EnvInfo: payment_gateway.EnvInfo{
-					SourcePlatform:    "IPG",
-					TerminalType:      webTerminalType,
-					OrderTerminalType: &webTerminalType,
-				},
-			},
-		}).
-		Execute()
+// CancelOrder voids an unpaid order using DANA CancelOrder API and records it as CANCELLED
+func (s *Service) CancelOrder(ctx context.Context, params CancelOrderRequestParams) (*payment_gateway.CancelOrderResponse, error) {
+	result, err := s.cancelOrder(ctx, params)
 	if err != nil {
 		return nil, err
 	}
-	return paymentMethod, nil
+	s.updateOrderStatus(ctx, params.PartnerReferenceNo, repository.StatusCancelled)
+	return result, nil
 }
 
-func (s *Service) GetOrder(ctx context.Context, partnerReferenceNo string) (*payment_gateway.QueryPaymentResponse, error) {
-	danaClient := dana.InitData()
+// cancelOrder voids an unpaid order at DANA, leaving the ledger status to the caller
+func (s *Service) cancelOrder(ctx context.Context, params CancelOrderRequestParams) (*payment_gateway.CancelOrderResponse, error) {
+	// Use merchant ID from params, the ledger or fallback to the default merchant
+	merchant, merchantID, err := s.merchantForOrder(ctx, params.PartnerReferenceNo, params.MerchantID)
+	if err != nil {
+		return nil, err
+	}
 
-	merchantID := os.Getenv("DANA_MERCHANT_ID")
+	// Validate required fields
+	if params.PartnerReferenceNo == "" {
+		return nil, fmt.Errorf("partnerReferenceNo is required")
+	}
+	if merchantID == "" {
+		return nil, fmt.Errorf("merchantId is required")
+	}
 
-	order, _, err := danaClient.PaymentGatewayAPI.QueryPayment(ctx).
-		QueryPaymentRequest(payment_gateway.QueryPaymentRequest{
-			OriginalPartnerReferenceNo: &partnerReferenceNo,
+	// Normalize the optional amount to the exact format DANA requires
+	var formattedAmount *payment_gateway.Money
+	if params.Amount != nil {
+		amount, err := money.FromGateway(*params.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("amount: %w", err)
+		}
+		gatewayAmount := amount.Gateway()
+		formattedAmount = &gatewayAmount
+	}
+
+	result, _, err := merchant.Client.PaymentGatewayAPI.CancelOrder(ctx).
+		CancelOrderRequest(payment_gateway.CancelOrderRequest{
+			OriginalPartnerReferenceNo: params.PartnerReferenceNo,
 			MerchantId:                 merchantID,
-			ServiceCode:                "54",
+			Reason:                     params.Reason,
+			Amount:                     formattedAmount,
 		}).
 		Execute()
 	if err != nil {
 		return nil, err
 	}
-	return order, nil
+	return result, nil
 }
@@ -0,0 +1,420 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dana-id/dana-go/payment_gateway/v1"
+	"github.com/riyanathariq/dana-enterprise/internal/config"
+	"github.com/riyanathariq/dana-enterprise/internal/errs"
+	"github.com/riyanathariq/dana-enterprise/internal/money"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
+	danaSDK "github.com/riyanathariq/dana-enterprise/internal/sdk/dana"
+	"github.com/riyanathariq/dana-enterprise/internal/sdk/dana/danatest"
+)
+
+// newGatewayService creates a service talking to a fake DANA gateway, with retries and the circuit breaker off
+// so every call reaches the gateway exactly once
+func newGatewayService(t *testing.T) (*Service, *danatest.Gateway, *repository.MemoryOrderRepository) {
+	t.Helper()
+	gw := danatest.NewGateway()
+	t.Cleanup(gw.Close)
+	gw.Setenv(t.Setenv)
+	t.Setenv("DANA_RETRY_MAX_ATTEMPTS", "1")
+	t.Setenv("DANA_BREAKER_FAILURE_THRESHOLD", "0")
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+	merchants, err := danaSDK.NewRegistry(cfg)
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	ledger := repository.NewMemoryOrderRepository()
+	return NewService(cfg, merchants, ledger, ledger), gw, ledger
+}
+
+var testUrlParams = []payment_gateway.UrlParam{
+	{Url: "https://merchant.example/return", Type: "PAY_RETURN", IsDeeplink: "N"},
+	{Url: "https://merchant.example/notify", Type: "NOTIFICATION", IsDeeplink: "N"},
+}
+
+// createHostedOrder creates a hosted checkout order of IDR 10.000 at the gateway
+func createHostedOrder(t *testing.T, s *Service, ref string) *CreatedOrder {
+	t.Helper()
+	created, err := s.CreateOrderHostedCheckout(context.Background(), CreateOrderRequestParams{
+		PartnerReferenceNo: ref,
+		Amount:             payment_gateway.Money{Value: "10000", Currency: "IDR"},
+		UrlParams:          testUrlParams,
+	})
+	if err != nil {
+		t.Fatalf("CreateOrderHostedCheckout: %v", err)
+	}
+	return created
+}
+
+// createPaidOrder creates an order and marks it paid at the gateway and in the ledger
+func createPaidOrder(t *testing.T, s *Service, gw *danatest.Gateway, ref string) {
+	t.Helper()
+	createHostedOrder(t, s, ref)
+	if err := gw.SetOrderStatus(ref, danatest.StatusSuccess); err != nil {
+		t.Fatalf("SetOrderStatus: %v", err)
+	}
+	if _, err := s.GetOrder(context.Background(), ref); err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+}
+
+// ledgerStatus returns the ledger status of an order
+func ledgerStatus(t *testing.T, ledger repository.OrderRepository, ref string) string {
+	t.Helper()
+	order, err := ledger.Get(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("Get %s: %v", ref, err)
+	}
+	return order.Status
+}
+
+// wantDanaCode fails the test unless err is a DANA error with responseCode
+func wantDanaCode(t *testing.T, err error, responseCode string) {
+	t.Helper()
+	danaErr, ok := danaSDK.AsDanaError(err)
+	if !ok {
+		t.Fatalf("err = %v, want DANA error %s", err, responseCode)
+	}
+	if danaErr.ResponseCode != responseCode {
+		t.Fatalf("responseCode = %s, want %s", danaErr.ResponseCode, responseCode)
+	}
+}
+
+func TestCreateOrderHostedCheckout(t *testing.T) {
+	s, gw, ledger := newGatewayService(t)
+
+	created := createHostedOrder(t, s, "ORDER-HOSTED-1")
+	if created.ReferenceNo == "" {
+		t.Error("ReferenceNo is empty")
+	}
+	if !strings.HasPrefix(created.WebRedirectURL, gw.URL()) {
+		t.Errorf("WebRedirectURL = %q, want a gateway checkout URL", created.WebRedirectURL)
+	}
+
+	order, err := ledger.Get(context.Background(), "ORDER-HOSTED-1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if order.Status != repository.StatusInitiated || order.CheckoutType != repository.CheckoutTypeHosted {
+		t.Errorf("ledger order = %s %s, want %s %s", order.CheckoutType, order.Status, repository.CheckoutTypeHosted, repository.StatusInitiated)
+	}
+	if order.MerchantID != danatest.TestMerchantID || order.AmountValue != "10000.00" {
+		t.Errorf("ledger order = %s %s, want %s 10000.00", order.MerchantID, order.AmountValue, danatest.TestMerchantID)
+	}
+	if order.AmountMinor != 1000000 {
+		t.Errorf("ledger amountMinor = %d, want 1000000", order.AmountMinor)
+	}
+	if n := len(gw.Requests(danatest.OpCreateOrder)); n != 1 {
+		t.Errorf("create order requests = %d, want 1", n)
+	}
+}
+
+func TestCreateOrderCustomCheckout(t *testing.T) {
+	s, _, ledger := newGatewayService(t)
+
+	created, err := s.CreateOrderCustomCheckout(context.Background(), CreateOrderRequestParams{
+		PartnerReferenceNo: "ORDER-CUSTOM-1",
+		Amount:             payment_gateway.Money{Value: "25000", Currency: "IDR"},
+		PayOptionDetails: []payment_gateway.PayOptionDetail{{
+			PayMethod:   "VIRTUAL_ACCOUNT",
+			PayOption:   "VIRTUAL_ACCOUNT_BCA",
+			TransAmount: payment_gateway.Money{Value: "25000", Currency: "IDR"},
+		}},
+		UrlParams: testUrlParams,
+	})
+	if err != nil {
+		t.Fatalf("CreateOrderCustomCheckout: %v", err)
+	}
+	if created.CheckoutType != repository.CheckoutTypeCustom || len(created.PayOptionDetails) != 1 {
+		t.Errorf("created = %+v, want a custom checkout order with one pay option", created)
+	}
+
+	order, err := ledger.Get(context.Background(), "ORDER-CUSTOM-1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if order.CheckoutType != repository.CheckoutTypeCustom || order.Status != repository.StatusInitiated {
+		t.Errorf("ledger order = %s %s, want %s %s", order.CheckoutType, order.Status, repository.CheckoutTypeCustom, repository.StatusInitiated)
+	}
+}
+
+func TestCreateOrderScriptedError(t *testing.T) {
+	s, gw, ledger := newGatewayService(t)
+	gw.Enqueue(danatest.OpCreateOrder, danatest.ScriptedResponse{
+		StatusCode:      409,
+		ResponseCode:    "4095401",
+		ResponseMessage: "Inconsistent Request",
+	})
+
+	_, err := s.CreateOrderHostedCheckout(context.Background(), CreateOrderRequestParams{
+		PartnerReferenceNo: "ORDER-SCRIPTED-1",
+		Amount:             payment_gateway.Money{Value: "10000", Currency: "IDR"},
+		UrlParams:          testUrlParams,
+	})
+	wantDanaCode(t, err, "4095401")
+	if _, err := ledger.Get(context.Background(), "ORDER-SCRIPTED-1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("ledger Get err = %v, want ErrNotFound for a rejected order", err)
+	}
+}
+
+func TestGetOrderSyncsLedgerStatus(t *testing.T) {
+	s, gw, ledger := newGatewayService(t)
+	createHostedOrder(t, s, "ORDER-QUERY-1")
+
+	if err := gw.SetOrderStatus("ORDER-QUERY-1", danatest.StatusSuccess); err != nil {
+		t.Fatalf("SetOrderStatus: %v", err)
+	}
+	if _, err := s.GetOrder(context.Background(), "ORDER-QUERY-1"); err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if got := ledgerStatus(t, ledger, "ORDER-QUERY-1"); got != repository.StatusSuccess {
+		t.Errorf("ledger status = %s, want %s", got, repository.StatusSuccess)
+	}
+}
+
+func TestGetPaymentMethod(t *testing.T) {
+	s, gw, _ := newGatewayService(t)
+	ctx := context.Background()
+	amount, err := money.Parse("50000", "IDR")
+	if err != nil {
+		t.Fatalf("money.Parse: %v", err)
+	}
+
+	methods, err := s.GetPaymentMethod(ctx, PaymentMethodParams{Amount: amount})
+	if err != nil {
+		t.Fatalf("GetPaymentMethod: %v", err)
+	}
+	if len(methods.Methods) != 4 || methods.MerchantID != danatest.TestMerchantID {
+		t.Errorf("methods = %+v, want the 4 gateway methods of %s", methods, danatest.TestMerchantID)
+	}
+
+	// Buyer consultations are not cached, so the scripted error is returned
+	gw.Enqueue(danatest.OpConsultPay, danatest.ScriptedResponse{StatusCode: 400, ResponseCode: "4000002", ResponseMessage: "Invalid Mandatory Field"})
+	_, err = s.GetPaymentMethod(ctx, PaymentMethodParams{Amount: amount, ExternalUserID: "USER-1"})
+	wantDanaCode(t, err, "4000002")
+}
+
+func TestCancelOrder(t *testing.T) {
+	s, _, ledger := newGatewayService(t)
+	createHostedOrder(t, s, "ORDER-CANCEL-1")
+
+	if _, err := s.CancelOrder(context.Background(), CancelOrderRequestParams{PartnerReferenceNo: "ORDER-CANCEL-1"}); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if got := ledgerStatus(t, ledger, "ORDER-CANCEL-1"); got != repository.StatusCancelled {
+		t.Errorf("ledger status = %s, want %s", got, repository.StatusCancelled)
+	}
+
+	// A second cancel is rejected by DANA and leaves the ledger untouched
+	if _, err := s.CancelOrder(context.Background(), CancelOrderRequestParams{PartnerReferenceNo: "ORDER-CANCEL-1"}); err == nil {
+		t.Fatal("second CancelOrder succeeded, want an error")
+	}
+	if got := ledgerStatus(t, ledger, "ORDER-CANCEL-1"); got != repository.StatusCancelled {
+		t.Errorf("ledger status = %s, want %s", got, repository.StatusCancelled)
+	}
+}
+
+func TestRefundOrder(t *testing.T) {
+	s, gw, _ := newGatewayService(t)
+	createPaidOrder(t, s, gw, "ORDER-REFUND-1")
+
+	refund, err := s.RefundOrder(context.Background(), RefundOrderRequestParams{
+		PartnerReferenceNo: "ORDER-REFUND-1",
+		PartnerRefundNo:    "REFUND-1",
+		Amount:             payment_gateway.Money{Value: "4000", Currency: "IDR"},
+	})
+	if err != nil {
+		t.Fatalf("RefundOrder: %v", err)
+	}
+	if refund.Status != repository.RefundStatusSuccess || refund.RefundNo == "" || refund.AmountValue != "4000.00" {
+		t.Errorf("refund = %+v, want a successful refund of 4000.00", refund)
+	}
+
+	stored, err := s.GetRefund(context.Background(), "REFUND-1")
+	if err != nil {
+		t.Fatalf("GetRefund: %v", err)
+	}
+	if stored.RefundNo != refund.RefundNo {
+		t.Errorf("stored refundNo = %s, want %s", stored.RefundNo, refund.RefundNo)
+	}
+
+	_, err = s.RefundOrder(context.Background(), RefundOrderRequestParams{
+		PartnerReferenceNo: "ORDER-REFUND-1",
+		PartnerRefundNo:    "REFUND-1",
+		Amount:             payment_gateway.Money{Value: "1000", Currency: "IDR"},
+	})
+	if !errors.Is(err, errs.ErrDuplicateRefund) {
+		t.Errorf("duplicate refund err = %v, want errs.ErrDuplicateRefund", err)
+	}
+
+	_, err = s.RefundOrder(context.Background(), RefundOrderRequestParams{
+		PartnerReferenceNo: "ORDER-REFUND-1",
+		PartnerRefundNo:    "REFUND-2",
+		Amount:             payment_gateway.Money{Value: "6001", Currency: "IDR"},
+	})
+	if !errors.Is(err, errs.ErrRefundExceedsAmount) {
+		t.Errorf("exceeding refund err = %v, want errs.ErrRefundExceedsAmount", err)
+	}
+
+	if got := len(gw.Requests(danatest.OpRefundOrder)); got < 1 {
+		t.Errorf("refund requests = %d, want at least 1", got)
+	}
+}
+
+func TestRefundOrderRejectedReleasesReservation(t *testing.T) {
+	s, gw, _ := newGatewayService(t)
+	createPaidOrder(t, s, gw, "ORDER-REFUND-2")
+	gw.Enqueue(danatest.OpRefundOrder, danatest.ScriptedResponse{
+		StatusCode:      403,
+		ResponseCode:    "4035815",
+		ResponseMessage: "Transaction Not Permitted",
+	})
+
+	params := RefundOrderRequestParams{
+		PartnerReferenceNo: "ORDER-REFUND-2",
+		PartnerRefundNo:    "REFUND-3",
+		Amount:             payment_gateway.Money{Value: "10000", Currency: "IDR"},
+	}
+	_, err := s.RefundOrder(context.Background(), params)
+	wantDanaCode(t, err, "4035815")
+	if _, err := s.GetRefund(context.Background(), "REFUND-3"); !errors.Is(err, errs.ErrRefundNotFound) {
+		t.Errorf("GetRefund err = %v, want errs.ErrRefundNotFound after a rejected refund", err)
+	}
+
+	// The full amount is refundable again once the rejected refund is released
+	if _, err := s.RefundOrder(context.Background(), params); err != nil {
+		t.Fatalf("retried RefundOrder: %v", err)
+	}
+}
+
+func TestRefundOrderResolvesUnknownOutcome(t *testing.T) {
+	s, gw, _ := newGatewayService(t)
+	ctx := context.Background()
+	createPaidOrder(t, s, gw, "ORDER-REFUND-3")
+	gw.Enqueue(danatest.OpRefundOrder, danatest.ScriptedResponse{StatusCode: 500, ResponseCode: "5005801", ResponseMessage: "General Error"})
+
+	refund, err := s.RefundOrder(ctx, RefundOrderRequestParams{
+		PartnerReferenceNo: "ORDER-REFUND-3",
+		PartnerRefundNo:    "REFUND-4",
+		Amount:             payment_gateway.Money{Value: "2500", Currency: "IDR"},
+	})
+	if err != nil {
+		t.Fatalf("RefundOrder: %v", err)
+	}
+	if refund.Status != repository.RefundStatusPending {
+		t.Fatalf("refund status = %s, want %s", refund.Status, repository.RefundStatusPending)
+	}
+
+	NewReconciler(s, config.ReconcilerConfig{Concurrency: 1}).ReconcileOnce(ctx)
+
+	stored, err := s.GetRefund(ctx, "REFUND-4")
+	if err != nil {
+		t.Fatalf("GetRefund: %v", err)
+	}
+	if stored.Status != repository.RefundStatusSuccess || stored.RefundNo == "" {
+		t.Errorf("refund = %+v, want it resolved as SUCCESS", stored)
+	}
+}
+
+func TestReconcilerKeepsRefundRejectedOnResend(t *testing.T) {
+	s, gw, ledger := newGatewayService(t)
+	ctx := context.Background()
+	createPaidOrder(t, s, gw, "ORDER-REFUND-4")
+
+	refund, err := s.RefundOrder(ctx, RefundOrderRequestParams{
+		PartnerReferenceNo: "ORDER-REFUND-4",
+		PartnerRefundNo:    "REFUND-5",
+		Amount:             payment_gateway.Money{Value: "6000", Currency: "IDR"},
+	})
+	if err != nil {
+		t.Fatalf("RefundOrder: %v", err)
+	}
+	// DANA refunded, but the response was lost, so the ledger still has the refund pending
+	refund.Status = repository.RefundStatusPending
+	refund.RefundNo = ""
+	if err := ledger.UpdateRefund(ctx, refund); err != nil {
+		t.Fatalf("UpdateRefund: %v", err)
+	}
+
+	// The resend is refused because DANA already knows REFUND-5
+	NewReconciler(s, config.ReconcilerConfig{Concurrency: 1}).ReconcileOnce(ctx)
+	if n := len(gw.Requests(danatest.OpRefundOrder)); n != 2 {
+		t.Fatalf("refund requests = %d, want 2", n)
+	}
+
+	stored, err := s.GetRefund(ctx, "REFUND-5")
+	if err != nil {
+		t.Fatalf("GetRefund = %v, want the reservation to survive the rejected resend", err)
+	}
+	if stored.Status != repository.RefundStatusPending {
+		t.Errorf("refund status = %s, want %s", stored.Status, repository.RefundStatusPending)
+	}
+	_, err = s.RefundOrder(ctx, RefundOrderRequestParams{
+		PartnerReferenceNo: "ORDER-REFUND-4",
+		PartnerRefundNo:    "REFUND-6",
+		Amount:             payment_gateway.Money{Value: "6000", Currency: "IDR"},
+	})
+	if !errors.Is(err, errs.ErrRefundExceedsAmount) {
+		t.Errorf("err = %v, want errs.ErrRefundExceedsAmount while REFUND-5 is pending", err)
+	}
+}
+
+func TestReconcilerReleasesRefundNotMade(t *testing.T) {
+	s, gw, _ := newGatewayService(t)
+	ctx := context.Background()
+	createPaidOrder(t, s, gw, "ORDER-REFUND-5")
+	gw.Enqueue(danatest.OpRefundOrder, danatest.ScriptedResponse{StatusCode: 500, ResponseCode: "5005801", ResponseMessage: "General Error"})
+	gw.Enqueue(danatest.OpRefundOrder, danatest.ScriptedResponse{StatusCode: 404, ResponseCode: "4045801", ResponseMessage: "Transaction Not Found"})
+
+	refund, err := s.RefundOrder(ctx, RefundOrderRequestParams{
+		PartnerReferenceNo: "ORDER-REFUND-5",
+		PartnerRefundNo:    "REFUND-7",
+		Amount:             payment_gateway.Money{Value: "2500", Currency: "IDR"},
+	})
+	if err != nil {
+		t.Fatalf("RefundOrder: %v", err)
+	}
+	if refund.Status != repository.RefundStatusPending {
+		t.Fatalf("refund status = %s, want %s", refund.Status, repository.RefundStatusPending)
+	}
+
+	NewReconciler(s, config.ReconcilerConfig{Concurrency: 1}).ReconcileOnce(ctx)
+
+	if _, err := s.GetRefund(ctx, "REFUND-7"); !errors.Is(err, errs.ErrRefundNotFound) {
+		t.Errorf("GetRefund = %v, want errs.ErrRefundNotFound once DANA does not know the order", err)
+	}
+}
+
+func TestReconcilerExpiresOverdueOrder(t *testing.T) {
+	s, gw, ledger := newGatewayService(t)
+	ctx := context.Background()
+	validUpTo := "2020-01-01T00:00:00+07:00"
+	_, err := s.CreateOrderHostedCheckout(ctx, CreateOrderRequestParams{
+		PartnerReferenceNo: "ORDER-EXPIRED-1",
+		Amount:             payment_gateway.Money{Value: "10000", Currency: "IDR"},
+		UrlParams:          testUrlParams,
+		ValidUpTo:          &validUpTo,
+	})
+	if err != nil {
+		t.Fatalf("CreateOrderHostedCheckout: %v", err)
+	}
+
+	NewReconciler(s, config.ReconcilerConfig{Concurrency: 1}).ReconcileOnce(ctx)
+
+	if n := len(gw.Requests(danatest.OpCancelOrder)); n != 1 {
+		t.Errorf("cancel requests = %d, want 1", n)
+	}
+	if status := ledgerStatus(t, ledger, "ORDER-EXPIRED-1"); status != repository.StatusExpired {
+		t.Errorf("ledger status = %s, want %s", status, repository.StatusExpired)
+	}
+}
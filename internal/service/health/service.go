@@ -0,0 +1,206 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/riyanathariq/dana-enterprise/internal/config"
+	danaSDK "github.com/riyanathariq/dana-enterprise/internal/sdk/dana"
+	"github.com/riyanathariq/dana-enterprise/internal/service/dana/merchant"
+)
+
+// Check statuses, from best to worst
+// A degraded check is reported but keeps the API ready, a failed check makes it not ready
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusFail     = "fail"
+)
+
+// ledgerTimeout bounds the order ledger ping, so a stuck database fails the check instead of hanging it
+const ledgerTimeout = 2 * time.Second
+
+// danaProbeTimeout bounds the DANA probe, including retries of the merchant resource query
+const danaProbeTimeout = 10 * time.Second
+
+// Pinger is a dependency that can report whether it is reachable, such as the order ledger
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Check is the outcome of one readiness check
+type Check struct {
+	Name       string      `json:"name"`
+	MerchantID string      `json:"merchant_id,omitempty"`
+	Status     string      `json:"status"`
+	LatencyMs  float64     `json:"latency_ms"`
+	Error      string      `json:"error,omitempty"`
+	CheckedAt  *time.Time  `json:"checked_at,omitempty"` // Set on cached checks
+	Details    interface{} `json:"details,omitempty"`
+}
+
+// Report is the outcome of every readiness check, Status is the worst check status
+type Report struct {
+	Status string  `json:"status"`
+	Checks []Check `json:"checks"`
+}
+
+// Service runs the checks behind /ready
+type Service struct {
+	merchants       *danaSDK.Registry
+	merchantService *merchant.Service
+	ledger          Pinger
+	cfg             config.ReadinessConfig
+
+	probeMu sync.Mutex
+	probe   *Check // Last DANA probe, reused until it is older than cfg.ProbeTTL
+}
+
+func NewService(merchants *danaSDK.Registry, merchantService *merchant.Service, ledger Pinger, cfg config.ReadinessConfig) *Service {
+	return &Service{
+		merchants:       merchants,
+		merchantService: merchantService,
+		ledger:          ledger,
+		cfg:             cfg,
+	}
+}
+
+// Check runs every readiness check
+// Key checks repeat the parsing done at startup, so keys changed underneath a running process are caught
+func (s *Service) Check(ctx context.Context) Report {
+	checks := []Check{s.checkLedger(ctx)}
+	for _, m := range s.merchants.All() {
+		checks = append(checks, checkPrivateKey(m), checkPublicKey(m))
+	}
+	checks = append(checks, s.checkBreaker())
+	if s.cfg.ProbeDana {
+		checks = append(checks, s.probeDana(ctx))
+	}
+
+	report := Report{Status: StatusOK, Checks: checks}
+	for _, check := range checks {
+		if severity(check.Status) > severity(report.Status) {
+			report.Status = check.Status
+		}
+	}
+	return report
+}
+
+// checkLedger pings the order ledger
+func (s *Service) checkLedger(ctx context.Context) Check {
+	ctx, cancel := context.WithTimeout(ctx, ledgerTimeout)
+	defer cancel()
+	return timed(Check{Name: "ledger"}, func() (string, error) {
+		if err := s.ledger.Ping(ctx); err != nil {
+			return StatusFail, err
+		}
+		return StatusOK, nil
+	})
+}
+
+// checkPrivateKey parses the private key DANA requests of a merchant are signed with
+func checkPrivateKey(m *danaSDK.Merchant) Check {
+	return timed(Check{Name: "private_key", MerchantID: m.ID}, func() (string, error) {
+		if _, err := danaSDK.ParsePrivateKey(m.Config.PrivateKey); err != nil {
+			return StatusFail, err
+		}
+		return StatusOK, nil
+	})
+}
+
+// checkPublicKey parses the DANA public key webhooks of a merchant are verified with
+// A missing key only degrades the API: orders can still be created, and the reconciler picks up their status
+func checkPublicKey(m *danaSDK.Merchant) Check {
+	return timed(Check{Name: "public_key", MerchantID: m.ID}, func() (string, error) {
+		if m.Config.PublicKey == "" {
+			return StatusDegraded, errors.New("DANA_PUBLIC_KEY is not set, webhooks cannot be verified")
+		}
+		if _, err := danaSDK.ParsePublicKey(m.Config.PublicKey); err != nil {
+			return StatusFail, err
+		}
+		return StatusOK, nil
+	})
+}
+
+// checkBreaker reports the DANA circuit breaker, which degrades but does not fail readiness
+// since ledger reads and webhooks keep working while DANA is failing
+func (s *Service) checkBreaker() Check {
+	breaker := s.merchants.Breaker().Status()
+	check := Check{Name: "circuit_breaker", Status: StatusOK, Details: breaker}
+	if breaker.State != danaSDK.BreakerClosed {
+		check.Status = StatusDegraded
+		check.Error = "DANA calls are paused by the circuit breaker"
+	}
+	return check
+}
+
+// probeDana queries the default merchant's resources at DANA, reusing the last result for cfg.ProbeTTL
+// so frequent probes do not load DANA. Rejected credentials fail the check; DANA being unreachable
+// only degrades it, as every instance would otherwise be taken out of the load balancer at once
+func (s *Service) probeDana(ctx context.Context) Check {
+	s.probeMu.Lock()
+	defer s.probeMu.Unlock()
+
+	if s.probe != nil && time.Since(*s.probe.CheckedAt) < s.cfg.ProbeTTL {
+		return *s.probe
+	}
+
+	m := s.merchants.Default()
+	if m == nil || m.ID == "" {
+		return Check{Name: "dana", Status: StatusDegraded, Error: "DANA_MERCHANT_ID is not set, nothing to probe"}
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, danaProbeTimeout)
+	defer cancel()
+	check := timed(Check{Name: "dana", MerchantID: m.ID}, func() (string, error) {
+		_, err := s.merchantService.GetMerchantInfo(probeCtx, m.ID, true)
+		return probeStatus(err), err
+	})
+
+	// A probe abandoned by the caller says nothing about DANA
+	if ctx.Err() == nil {
+		checkedAt := time.Now()
+		check.CheckedAt = &checkedAt
+		s.probe = &check
+	}
+	return check
+}
+
+// probeStatus classifies the outcome of the DANA probe
+func probeStatus(err error) string {
+	if err == nil {
+		return StatusOK
+	}
+	if danaErr, ok := danaSDK.AsDanaError(err); ok {
+		// 401 is a rejected signature or client secret, 404xx08 an unknown merchant
+		if danaErr.HTTPStatus == 401 || (danaErr.HTTPStatus == 404 && danaErr.CaseCode() == "08") {
+			return StatusFail
+		}
+	}
+	return StatusDegraded
+}
+
+// timed runs fn and records its status, error and latency in check
+func timed(check Check, fn func() (string, error)) Check {
+	start := time.Now()
+	status, err := fn()
+	check.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	check.Status = status
+	if err != nil {
+		check.Error = err.Error()
+	}
+	return check
+}
+
+// severity orders check statuses from best to worst
+func severity(status string) int {
+	switch status {
+	case StatusOK:
+		return 0
+	case StatusDegraded:
+		return 1
+	}
+	return 2
+}
@@ -0,0 +1,147 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/riyanathariq/dana-enterprise/internal/model"
+	"github.com/riyanathariq/dana-enterprise/internal/money"
+)
+
+// MaxOrderValidity is the furthest in the future DANA accepts validUpTo
+const MaxOrderValidity = 7 * 24 * time.Hour
+
+// ValidUpToLayout is the validUpTo format DANA accepts, e.g. 2025-11-05T12:00:00+07:00
+// The layout parses any offset, validateValidUpTo then requires validUpToOffset
+const ValidUpToLayout = "2006-01-02T15:04:05-07:00"
+
+// validUpToOffset is the offset DANA requires of validUpTo, Jakarta time (+07:00)
+const validUpToOffset = 7 * 60 * 60
+
+// Field error codes
+const (
+	CodeInvalidAmount       = "INVALID_AMOUNT"
+	CodeUnsupportedCurrency = "UNSUPPORTED_CURRENCY"
+	CodeCurrencyMismatch    = "CURRENCY_MISMATCH"
+	CodeAmountMismatch      = "AMOUNT_MISMATCH"
+	CodeFeeExceedsAmount    = "FEE_EXCEEDS_TRANS_AMOUNT"
+	CodeUnknownPayMethod    = "UNKNOWN_PAY_METHOD"
+	CodeUnknownPayOption    = "UNKNOWN_PAY_OPTION"
+	CodeRequired            = "REQUIRED"
+	CodeInvalidFormat       = "INVALID_FORMAT"
+	CodeExpired             = "EXPIRED"
+	CodeTooFarInFuture      = "TOO_FAR_IN_FUTURE"
+)
+
+// fieldErrors collects the errors of a request in field order
+type fieldErrors []model.FieldError
+
+func (e *fieldErrors) add(field, code, message string) {
+	*e = append(*e, model.FieldError{Field: field, Code: code, Message: message})
+}
+
+// ValidateCreateOrder checks the amounts, pay options and expiry of a create order request
+// against DANA's rules, returning every invalid field rather than stopping at the first
+// Presence of required fields is checked by request binding beforehand
+func ValidateCreateOrder(req *model.CreateOrderRequest, now time.Time) []model.FieldError {
+	var errs fieldErrors
+
+	amount, amountOK := parseMoney(&errs, "amount", req.Amount)
+
+	// The transAmounts are only added up when all of them are valid, a partial total would be misleading
+	total, totalOK := money.Money{}, amountOK
+	if amountOK {
+		total, _ = money.New(0, amount.Currency())
+	}
+	for i, pod := range req.PayOptionDetails {
+		field := fmt.Sprintf("pay_option_details[%d]", i)
+		validatePayOption(&errs, field, pod)
+
+		transAmount, ok := parseMoney(&errs, field+".trans_amount", pod.TransAmount)
+		if ok && amountOK && transAmount.Currency() != amount.Currency() {
+			errs.add(field+".trans_amount.currency", CodeCurrencyMismatch,
+				fmt.Sprintf("must be %s, the currency of amount", amount.Currency()))
+			ok = false
+		}
+		if ok && totalOK {
+			total, _ = total.Add(transAmount)
+		}
+		totalOK = totalOK && ok
+
+		if pod.FeeAmount == nil {
+			continue
+		}
+		feeAmount, feeOK := parseMoney(&errs, field+".fee_amount", *pod.FeeAmount)
+		if !feeOK || !ok {
+			continue
+		}
+		if feeAmount.Currency() != transAmount.Currency() {
+			errs.add(field+".fee_amount.currency", CodeCurrencyMismatch,
+				fmt.Sprintf("must be %s, the currency of trans_amount", transAmount.Currency()))
+		} else if cmp, _ := feeAmount.Cmp(transAmount); cmp > 0 {
+			errs.add(field+".fee_amount.value", CodeFeeExceedsAmount,
+				fmt.Sprintf("fee %s exceeds trans_amount %s", feeAmount, transAmount))
+		}
+	}
+	if len(req.PayOptionDetails) > 0 && totalOK {
+		if cmp, _ := total.Cmp(amount); cmp != 0 {
+			errs.add("pay_option_details", CodeAmountMismatch,
+				fmt.Sprintf("trans_amount values add up to %s, amount is %s", total, amount))
+		}
+	}
+
+	if req.ValidUpTo != nil && *req.ValidUpTo != "" {
+		validateValidUpTo(&errs, "valid_up_to", *req.ValidUpTo, now)
+	}
+
+	return errs
+}
+
+// parseMoney parses an amount of a request, recording why it is invalid
+func parseMoney(errs *fieldErrors, field string, m model.MoneyRequest) (money.Money, bool) {
+	amount, err := money.Parse(m.Value, m.Currency)
+	switch {
+	case errors.Is(err, money.ErrUnsupportedCurrency):
+		errs.add(field+".currency", CodeUnsupportedCurrency, "only "+money.IDR+" is supported")
+		return money.Money{}, false
+	case err != nil:
+		errs.add(field+".value", CodeInvalidAmount, err.Error())
+		return money.Money{}, false
+	}
+	return amount, true
+}
+
+// validatePayOption checks the pay method and pay option against the catalogue, and the tokens the option needs
+func validatePayOption(errs *fieldErrors, field string, pod model.PayOptionDetailRequest) {
+	if !pod.PayMethod.Known() {
+		errs.add(field+".pay_method", CodeUnknownPayMethod, fmt.Sprintf("unknown pay method %q, see GET /api/v1/payment/options", pod.PayMethod))
+		return
+	}
+	info, ok := model.LookupPayOption(pod.PayMethod, pod.PayOption)
+	if !ok {
+		errs.add(field+".pay_option", CodeUnknownPayOption, fmt.Sprintf("%q is not a pay option of %s, see GET /api/v1/payment/options", pod.PayOption, pod.PayMethod))
+		return
+	}
+	if info.RequiresCardToken && (pod.CardToken == nil || *pod.CardToken == "") {
+		errs.add(field+".card_token", CodeRequired, "is required for "+info.DisplayName)
+	}
+	if info.RequiresMerchantToken && (pod.MerchantToken == nil || *pod.MerchantToken == "") {
+		errs.add(field+".merchant_token", CodeRequired, "is required for "+info.DisplayName)
+	}
+}
+
+// validateValidUpTo checks that validUpTo is a DANA timestamp in the next MaxOrderValidity
+func validateValidUpTo(errs *fieldErrors, field, validUpTo string, now time.Time) {
+	t, err := time.Parse(ValidUpToLayout, validUpTo)
+	if _, offset := t.Zone(); err != nil || offset != validUpToOffset {
+		errs.add(field, CodeInvalidFormat, "must be formatted as YYYY-MM-DDTHH:mm:ss+07:00")
+		return
+	}
+	switch {
+	case !t.After(now):
+		errs.add(field, CodeExpired, "must be in the future")
+	case t.Sub(now) > MaxOrderValidity:
+		errs.add(field, CodeTooFarInFuture, "must be at most 7 days in the future")
+	}
+}
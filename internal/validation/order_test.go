@@ -0,0 +1,203 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/riyanathariq/dana-enterprise/internal/model"
+)
+
+// testNow is 2025-11-05 12:00 in Jakarta
+var testNow = time.Date(2025, 11, 5, 5, 0, 0, 0, time.UTC)
+
+// idr returns an IDR amount of a request
+func idr(value string) model.MoneyRequest {
+	return model.MoneyRequest{Value: value, Currency: "IDR"}
+}
+
+// validOrder returns a valid create order request of IDR 10000.00, paid 6000.00 by BCA virtual account
+// and 4000.00 by QRIS, valid for a day
+func validOrder() *model.CreateOrderRequest {
+	fee := idr("1000.00")
+	validUpTo := "2025-11-06T12:00:00+07:00"
+	return &model.CreateOrderRequest{
+		PartnerReferenceNo: "ORDER-001",
+		Amount:             idr("10000.00"),
+		PayOptionDetails: []model.PayOptionDetailRequest{
+			{PayMethod: model.PayMethodVirtualAccount, PayOption: model.PayOptionVirtualAccountBCA, TransAmount: idr("6000.00"), FeeAmount: &fee},
+			{PayMethod: model.PayMethodNetworkPay, PayOption: model.PayOptionQRIS, TransAmount: idr("4000.00")},
+		},
+		ValidUpTo: &validUpTo,
+	}
+}
+
+// fieldCodes returns the field and code of each error, leaving out the messages
+func fieldCodes(errs []model.FieldError) [][2]string {
+	var codes [][2]string
+	for _, e := range errs {
+		codes = append(codes, [2]string{e.Field, e.Code})
+	}
+	return codes
+}
+
+func TestValidateCreateOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(req *model.CreateOrderRequest)
+		want   [][2]string
+	}{
+		{
+			name:   "valid",
+			modify: func(req *model.CreateOrderRequest) {},
+		},
+		{
+			name:   "hosted checkout without pay options",
+			modify: func(req *model.CreateOrderRequest) { req.PayOptionDetails = nil; req.ValidUpTo = nil },
+		},
+		{
+			name:   "invalid amount",
+			modify: func(req *model.CreateOrderRequest) { req.Amount = idr("10000.001") },
+			want:   [][2]string{{"amount.value", CodeInvalidAmount}},
+		},
+		{
+			name:   "unsupported currency",
+			modify: func(req *model.CreateOrderRequest) { req.Amount.Currency = "USD" },
+			want:   [][2]string{{"amount.currency", CodeUnsupportedCurrency}},
+		},
+		{
+			name:   "mismatched totals",
+			modify: func(req *model.CreateOrderRequest) { req.PayOptionDetails[1].TransAmount = idr("3999.99") },
+			want:   [][2]string{{"pay_option_details", CodeAmountMismatch}},
+		},
+		{
+			name:   "mixed currencies",
+			modify: func(req *model.CreateOrderRequest) { req.PayOptionDetails[1].TransAmount.Currency = "USD" },
+			want:   [][2]string{{"pay_option_details[1].trans_amount.currency", CodeUnsupportedCurrency}},
+		},
+		{
+			name: "fee in another currency",
+			modify: func(req *model.CreateOrderRequest) {
+				req.PayOptionDetails[0].FeeAmount = &model.MoneyRequest{Value: "1000.00", Currency: "USD"}
+			},
+			want: [][2]string{{"pay_option_details[0].fee_amount.currency", CodeUnsupportedCurrency}},
+		},
+		{
+			name:   "unknown pay method",
+			modify: func(req *model.CreateOrderRequest) { req.PayOptionDetails[0].PayMethod = "CASH" },
+			want:   [][2]string{{"pay_option_details[0].pay_method", CodeUnknownPayMethod}},
+		},
+		{
+			name:   "unknown pay option",
+			modify: func(req *model.CreateOrderRequest) { req.PayOptionDetails[0].PayOption = model.PayOptionQRIS },
+			want:   [][2]string{{"pay_option_details[0].pay_option", CodeUnknownPayOption}},
+		},
+		{
+			name: "missing card token",
+			modify: func(req *model.CreateOrderRequest) {
+				req.PayOptionDetails[1].PayOption = model.PayOptionCard
+			},
+			want: [][2]string{{"pay_option_details[1].card_token", CodeRequired}},
+		},
+		{
+			name: "missing merchant token",
+			modify: func(req *model.CreateOrderRequest) {
+				req.PayOptionDetails[1].PayMethod = model.PayMethodBalance
+				req.PayOptionDetails[1].PayOption = model.PayOptionNone
+			},
+			want: [][2]string{{"pay_option_details[1].merchant_token", CodeRequired}},
+		},
+		{
+			name: "fee exceeds trans amount",
+			modify: func(req *model.CreateOrderRequest) {
+				fee := idr("6000.01")
+				req.PayOptionDetails[0].FeeAmount = &fee
+			},
+			want: [][2]string{{"pay_option_details[0].fee_amount.value", CodeFeeExceedsAmount}},
+		},
+		{
+			name: "fee equal to trans amount",
+			modify: func(req *model.CreateOrderRequest) {
+				fee := idr("6000.00")
+				req.PayOptionDetails[0].FeeAmount = &fee
+			},
+		},
+		{
+			name:   "past valid_up_to",
+			modify: func(req *model.CreateOrderRequest) { *req.ValidUpTo = "2025-11-05T11:59:59+07:00" },
+			want:   [][2]string{{"valid_up_to", CodeExpired}},
+		},
+		{
+			name:   "valid_up_to now",
+			modify: func(req *model.CreateOrderRequest) { *req.ValidUpTo = "2025-11-05T12:00:00+07:00" },
+			want:   [][2]string{{"valid_up_to", CodeExpired}},
+		},
+		{
+			name:   "valid_up_to 7 days out",
+			modify: func(req *model.CreateOrderRequest) { *req.ValidUpTo = "2025-11-12T12:00:00+07:00" },
+		},
+		{
+			name:   "valid_up_to more than 7 days out",
+			modify: func(req *model.CreateOrderRequest) { *req.ValidUpTo = "2025-11-12T12:00:01+07:00" },
+			want:   [][2]string{{"valid_up_to", CodeTooFarInFuture}},
+		},
+		{
+			name:   "valid_up_to in UTC",
+			modify: func(req *model.CreateOrderRequest) { *req.ValidUpTo = "2025-11-06T05:00:00+00:00" },
+			want:   [][2]string{{"valid_up_to", CodeInvalidFormat}},
+		},
+		{
+			name:   "valid_up_to with Z",
+			modify: func(req *model.CreateOrderRequest) { *req.ValidUpTo = "2025-11-06T05:00:00Z" },
+			want:   [][2]string{{"valid_up_to", CodeInvalidFormat}},
+		},
+		{
+			name:   "valid_up_to with another offset",
+			modify: func(req *model.CreateOrderRequest) { *req.ValidUpTo = "2025-11-06T13:00:00+08:00" },
+			want:   [][2]string{{"valid_up_to", CodeInvalidFormat}},
+		},
+		{
+			name:   "valid_up_to without offset",
+			modify: func(req *model.CreateOrderRequest) { *req.ValidUpTo = "2025-11-06T12:00:00" },
+			want:   [][2]string{{"valid_up_to", CodeInvalidFormat}},
+		},
+		{
+			name:   "empty valid_up_to",
+			modify: func(req *model.CreateOrderRequest) { *req.ValidUpTo = "" },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validOrder()
+			tt.modify(req)
+			if got := fieldCodes(ValidateCreateOrder(req, testNow)); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ValidateCreateOrder = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCreateOrderReportsEveryField(t *testing.T) {
+	req := validOrder()
+	fee := idr("7000.00")
+	req.PayOptionDetails[0].FeeAmount = &fee
+	req.PayOptionDetails[0].TransAmount = idr("5000.00")
+	req.PayOptionDetails[1].PayMethod = "CASH"
+	req.PayOptionDetails = append(req.PayOptionDetails, model.PayOptionDetailRequest{
+		PayMethod:   model.PayMethodNetworkPay,
+		PayOption:   model.PayOptionCard,
+		TransAmount: idr("2000.00"),
+	})
+	*req.ValidUpTo = "2025-11-20T12:00:00+07:00"
+
+	want := []model.FieldError{
+		{Field: "pay_option_details[0].fee_amount.value", Code: CodeFeeExceedsAmount, Message: "fee 7000.00 exceeds trans_amount 5000.00"},
+		{Field: "pay_option_details[1].pay_method", Code: CodeUnknownPayMethod, Message: `unknown pay method "CASH", see GET /api/v1/payment/options`},
+		{Field: "pay_option_details[2].card_token", Code: CodeRequired, Message: "is required for Credit / Debit Card"},
+		{Field: "pay_option_details", Code: CodeAmountMismatch, Message: "trans_amount values add up to 11000.00, amount is 10000.00"},
+		{Field: "valid_up_to", Code: CodeTooFarInFuture, Message: "must be at most 7 days in the future"},
+	}
+	if got := ValidateCreateOrder(req, testNow); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ValidateCreateOrder =\n%v\nwant\n%v", got, want)
+	}
+}
@@ -1,14 +1,30 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/riyanathariq/dana-enterprise/internal/auth"
+	"github.com/riyanathariq/dana-enterprise/internal/config"
+	"github.com/riyanathariq/dana-enterprise/internal/handler"
+	"github.com/riyanathariq/dana-enterprise/internal/logging"
+	"github.com/riyanathariq/dana-enterprise/internal/middleware"
+	"github.com/riyanathariq/dana-enterprise/internal/ratelimit"
+	"github.com/riyanathariq/dana-enterprise/internal/repository"
 	"github.com/riyanathariq/dana-enterprise/internal/route"
-	"github.com/riyanathariq/dana-enterprise/package/dana"
+	danaSDK "github.com/riyanathariq/dana-enterprise/internal/sdk/dana"
+	"github.com/riyanathariq/dana-enterprise/internal/service/dana/merchant"
+	"github.com/riyanathariq/dana-enterprise/internal/service/dana/order"
+	"github.com/riyanathariq/dana-enterprise/internal/service/health"
 )
 
 func main() {
@@ -17,48 +33,131 @@ func main() {
 		log.Printf("⚠️  Warning: .env file not found: %v\n", err)
 	}
 
-	// Initialize Dana API Client
-	danaClient := dana.InitData()
-	if danaClient == nil {
-		log.Fatal("❌ Failed to initialize Dana API client")
+	// Load and validate configuration (YAML file, environment, flags)
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("❌ %v", err)
 	}
 
-	// Print configuration
-	fmt.Println("✅ Dana API Client initialized successfully!")
-	fmt.Println("📋 Configuration:")
-	if env := os.Getenv("DANA_ENV"); env != "" {
-		fmt.Printf("   - Environment: %s\n", env)
+	// Structured JSON logging with secrets and buyer identifiers redacted
+	slog.SetDefault(logging.New(os.Stdout, logging.Options{
+		Level:  cfg.Log.Level,
+		Format: cfg.Log.Format,
+	}))
+
+	// Initialize Dana API clients for every merchant (parses private keys once)
+	merchants, err := danaSDK.NewRegistry(cfg)
+	if err != nil {
+		slog.Error("failed to initialize Dana API clients", "error", err)
+		os.Exit(1)
+	}
+
+	// API clients allowed to call /api/v1
+	authenticator, err := auth.NewAuthenticator(cfg.Auth)
+	if err != nil {
+		slog.Error("failed to load API clients", "error", err)
+		os.Exit(1)
 	}
-	fmt.Printf("   - Client ID: %s\n", os.Getenv("DANA_CLIENT_ID"))
-	if merchantID := os.Getenv("DANA_MERCHANT_ID"); merchantID != "" {
-		fmt.Printf("   - Merchant ID: %s\n", merchantID)
+	if !authenticator.Enabled() {
+		slog.Warn("no API clients configured, /api/v1 is open to anyone who can reach the server", "hint", "set API_CLIENTS_PATH")
 	}
 
-	// Set Gin mode (production/release mode)
-	if os.Getenv("GIN_MODE") == "" {
-		// Default to release mode if not set
-		gin.SetMode(gin.ReleaseMode)
+	gin.SetMode(cfg.Server.GinMode)
+
+	// Open order ledger
+	orderRepository, err := repository.NewSQLiteOrderRepository(cfg.Server.DatabasePath)
+	if err != nil {
+		slog.Error("failed to open order ledger", "error", err)
+		os.Exit(1)
+	}
+	defer orderRepository.Close()
+
+	slog.Info("Dana API client initialized",
+		"environment", cfg.Dana.Env,
+		"client_id", cfg.Dana.ClientID,
+		"merchant_id", cfg.Dana.MerchantID,
+		"merchants", merchants.MerchantIDs(),
+		"order_ledger", cfg.Server.DatabasePath,
+		"log_level", cfg.Log.Level,
+		"api_clients", len(cfg.Auth.Clients),
+	)
+
+	// Stop background jobs on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	orderService := order.NewService(cfg, merchants, orderRepository, orderRepository)
+	merchantService := merchant.NewService(merchants, cfg.Merchant)
+
+	// Sync pending orders with DANA and expire them past validUpTo
+	var jobs sync.WaitGroup
+	if cfg.Reconciler.Enabled {
+		reconciler := order.NewReconciler(orderService, cfg.Reconciler)
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			reconciler.Run(ctx)
+		}()
 	}
 
 	// Setup routes
-	r := route.SetupRoutes()
+	danaHandler := handler.NewDanaHandler(cfg, merchants, merchantService, orderService)
+	healthHandler := handler.NewHealthHandler(health.NewService(merchants, merchantService, orderRepository, cfg.Readiness))
+	// Per-client rate limits, kept in memory so each instance limits separately
+	limiter := middleware.NewRateLimiter(ratelimit.NewMemoryStore(), cfg.RateLimit)
+	r := route.SetupRoutes(danaHandler, healthHandler, authenticator, limiter, orderRepository, cfg.Idempotency.TTL)
 
 	// Trust only localhost proxies in development
 	// In production, set specific trusted proxies
-	if os.Getenv("GIN_TRUSTED_PROXIES") != "" {
-		r.SetTrustedProxies([]string{os.Getenv("GIN_TRUSTED_PROXIES")})
+	if cfg.Server.TrustedProxies != "" {
+		r.SetTrustedProxies([]string{cfg.Server.TrustedProxies})
 	} else {
 		r.SetTrustedProxies(nil) // Don't trust all proxies
 	}
 
-	// Get port from environment or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "3150"
+	// Start server with timeouts so slow clients cannot hold connections open
+	srv := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           r,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
 	}
+	slog.Info("starting server", "port", cfg.Server.Port)
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- srv.ListenAndServe()
+	}()
 
-	// Start server
-	if err := r.Run(":" + port); err != nil {
-		log.Fatalf("❌ Failed to start server: %v", err)
+	select {
+	case err := <-serverErr:
+		slog.Error("failed to start server", "error", err)
+		stop()
+		jobs.Wait()
+		orderRepository.Close()
+		os.Exit(1)
+	case <-ctx.Done():
 	}
+
+	// A second signal kills the process without waiting
+	stop()
+	slog.Info("shutting down", "drain_delay", cfg.Server.DrainDelay.String(), "shutdown_timeout", cfg.Server.ShutdownTimeout.String())
+
+	// Fail readiness first so load balancers stop routing new requests here
+	healthHandler.Drain()
+	time.Sleep(cfg.Server.DrainDelay)
+
+	// Stop accepting connections and wait for in-flight requests, such as order creation at DANA
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("in-flight requests did not finish in time, closing connections", "error", err)
+		srv.Close()
+	}
+
+	// Let the reconciler finish the orders it is working on
+	jobs.Wait()
+	slog.Info("server stopped")
 }